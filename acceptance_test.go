@@ -51,7 +51,7 @@ func TestElasticsearchCliNonInteractive(t *testing.T) {
 			"CreateIndexWorks",
 			[]string{
 				"PUT",
-				"MyTestIndex",
+				"mytestindex",
 				"",
 			},
 			false,
@@ -61,7 +61,7 @@ func TestElasticsearchCliNonInteractive(t *testing.T) {
 			"DELETEIndexWorks",
 			[]string{
 				"DELETE",
-				"MyTestIndex",
+				"mytestindex",
 				"",
 			},
 			false,
@@ -71,7 +71,7 @@ func TestElasticsearchCliNonInteractive(t *testing.T) {
 			"CreateIndexWithBodyWorks",
 			[]string{
 				"PUT",
-				"MySettingsTestIndex",
+				"mysettingstestindex",
 				`{"settings": {"index": {"number_of_shards" : 1, "number_of_replicas" : 0} }}`,
 			},
 			false,
@@ -81,7 +81,7 @@ func TestElasticsearchCliNonInteractive(t *testing.T) {
 			"GetIndexSettingsucceeds",
 			[]string{
 				"GET",
-				"MySettingsTestIndex/_settings",
+				"mysettingstestindex/_settings",
 				"",
 			},
 			false,
@@ -91,7 +91,7 @@ func TestElasticsearchCliNonInteractive(t *testing.T) {
 			"DELETEIndexWorks",
 			[]string{
 				"DELETE",
-				"MySettingsTestIndex",
+				"mysettingstestindex",
 				"",
 			},
 			false,
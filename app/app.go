@@ -2,6 +2,8 @@ package app
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
@@ -13,27 +15,27 @@ import (
 	"github.com/marclop/elasticsearch-cli/cli"
 	"github.com/marclop/elasticsearch-cli/client"
 	"github.com/marclop/elasticsearch-cli/elasticsearch"
+	"github.com/marclop/elasticsearch-cli/history"
 	"github.com/marclop/elasticsearch-cli/poller"
+	"github.com/marclop/elasticsearch-cli/utils"
 )
 
+// clusterColors are used in interactive mode for the prompt of the different
+// cluster statuses
+var clusterColors = map[string]string{
+	"green":  "\x1b[32m",
+	"yellow": "\x1b[33m",
+	"red":    "\x1b[31m",
+}
+
 const (
-	// DefaultPrompt is used when the cluster status cannot be retrieved
-	DefaultPrompt = "\x1b[34melasticsearch> \x1b[0m"
-	// GreenPrompt is used when the cluster status is green
-	GreenPrompt = "\x1b[32melasticsearch> \x1b[0m"
-	// YellowPrompt is used when the cluster status is yellow
-	YellowPrompt = "\x1b[33melasticsearch> \x1b[0m"
-	// RedPrompt is used when the cluster status is red
-	RedPrompt = "\x1b[31melasticsearch> \x1b[0m"
+	// defaultColor is used when the cluster status cannot be retrieved
+	defaultColor = "\x1b[34m"
+	resetColor   = "\x1b[0m"
+	// continuationPrompt is used while a multi-line request body is being typed
+	continuationPrompt = defaultColor + "... " + resetColor
 )
 
-// clusterPrompts are used in interactive mode for different prompt colors
-var clusterPrompts = map[string]string{
-	"green":  GreenPrompt,
-	"yellow": YellowPrompt,
-	"red":    RedPrompt,
-}
-
 // Application contains the full application and its dependencies
 type Application struct {
 	config       *Config
@@ -42,8 +44,12 @@ type Application struct {
 	output       io.Writer
 	indexChannel chan []string
 	parser       *cli.InputParser
-	poller       Poller
-	repl         *readline.Instance
+	console      *cli.ConsoleParser
+	// filter is applied to the request being typed in interactive mode
+	filter  string
+	poller  Poller
+	repl    *readline.Instance
+	history *history.History
 }
 
 // Poller is the responsible for polling ElasticSearch and retrieving endpoints to autocomplete the CLI
@@ -61,25 +67,84 @@ type Formatter func(input *http.Response, verbose bool, interactive bool, writer
 
 // New creates a new instance of elasticsearch-cli from the passed Config
 func New(config *Config) (*Application, error) {
-	clientConfig, err := client.NewClientConfig(config.Host, config.Port, config.User, config.Pass, config.Timeout, config.Insecure)
+	httpClient, err := newClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	formatter, err := newFormatter(config)
+	if err != nil {
+		return nil, err
+	}
+
+	indicesChannel := make(chan []string, 1)
+	indexPoller := poller.NewIndexPoller(httpClient, indicesChannel, config.PollInterval)
+	return initialize(config, httpClient, formatter, indicesChannel, indexPoller, os.Stdout), nil
+}
+
+// newClient creates the HTTP client of the configured Elasticsearch endpoints
+func newClient(config *Config) (*client.HTTP, error) {
+	hosts, port, err := config.endpoints()
+	if err != nil {
+		return nil, err
+	}
+
+	user, pass, err := config.credentials()
+	if err != nil {
+		return nil, err
+	}
+	if pass == "" && config.basicAuth() {
+		log.Print("[WARNING]: ", fmt.Sprintf("no password set for user \"%s\", the requests are not authenticated", user))
+	}
+
+	clientConfig, err := client.NewClientConfig(hosts[0], port, user, pass, config.Timeout, config.tlsConfig())
 	if err != nil {
 		return nil, err
 	}
 
-	if config.Headers != nil {
-		for header, value := range config.Headers {
-			clientConfig.SetHeader(header, value)
+	for _, host := range hosts[1:] {
+		if err := clientConfig.AddHost(host, port); err != nil {
+			return nil, err
 		}
 	}
 
-	httpClient := client.NewHTTP(clientConfig, config.Client)
+	clientConfig.Retry = config.retryConfig()
+	clientConfig.Compress = config.Compress
+	if config.Proxy != "" {
+		if err := clientConfig.SetProxy(config.Proxy, config.NoProxy); err != nil {
+			return nil, err
+		}
+	}
+	clientConfig.APIKey = config.APIKey
+	clientConfig.Token = config.Token
+	if clientConfig.Signer, err = config.signer(); err != nil {
+		return nil, err
+	}
+
+	headers, err := config.headers()
 	if err != nil {
 		return nil, err
 	}
+	for header, value := range headers {
+		clientConfig.SetHeader(header, value)
+	}
 
-	indicesChannel := make(chan []string, 1)
-	indexPoller := poller.NewIndexPoller(httpClient, indicesChannel, config.PollInterval)
-	return initialize(config, httpClient, cli.Format, indicesChannel, indexPoller, os.Stdout), nil
+	httpClient := client.NewHTTP(clientConfig, config.Client)
+	if config.Sniff {
+		if err := httpClient.Sniff(); err != nil {
+			log.Print("[ERROR]: ", err)
+		}
+	}
+	return httpClient, nil
+}
+
+// newFormatter creates the Formatter of the configured output
+func newFormatter(config *Config) (Formatter, error) {
+	opts, err := config.formatOptions()
+	if err != nil {
+		return nil, err
+	}
+	return cli.NewFormatter(opts)
 }
 
 func initialize(config *Config, client *client.HTTP, f Formatter, c chan []string, w Poller, o io.Writer) *Application {
@@ -87,6 +152,7 @@ func initialize(config *Config, client *client.HTTP, f Formatter, c chan []strin
 	return &Application{
 		config:       config,
 		client:       client,
+		console:      cli.NewConsoleParser(),
 		formatFunc:   f,
 		indexChannel: c,
 		poller:       w,
@@ -102,48 +168,123 @@ func (app *Application) HandleCli(args []string) error {
 		return err
 	}
 
-	res, err := app.client.HandleCall(input.Method, input.URL, input.Body)
+	return app.HandleRequest(input)
+}
+
+// HandleRequest performs the already parsed request against the remote host
+// and formats its response
+func (app *Application) HandleRequest(input *cli.InputParser) error {
+	_, err := app.handleRequest(input)
+	return err
+}
+
+// handleRequest performs and formats the request, returning the response's
+// HTTP status code
+func (app *Application) handleRequest(input *cli.InputParser) (int, error) {
+	return app.handleRequestWith(input, app.formatFunc)
+}
+
+// handleRequestWith performs the request and formats it using f. When the
+// request fails with a 401 using basic authentication, the password is
+// prompted for and the request sent again, as long as its body can be
+// rewound.
+func (app *Application) handleRequestWith(input *cli.InputParser, f Formatter) (int, error) {
+	res, err := app.client.HandleCall(input.Method, input.Path, input.Query, input.Body)
+	for prompts := 0; err == nil && prompts < maxPasswordPrompts && app.repromptPassword(res, input.Body); prompts++ {
+		res, err = app.client.HandleCall(input.Method, input.Path, input.Query, input.Body)
+	}
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer res.Body.Close()
 
-	return app.formatFunc(res, app.config.Verbose, app.repl != nil, app.output)
+	// Deprecation warnings are printed regardless of the verbosity
+	for _, warning := range cli.Warnings(res) {
+		log.Print("[WARNING]: ", warning)
+	}
+
+	var output = app.pagedOutput()
+	defer output.Close()
+
+	return res.StatusCode, f(res, app.config.Verbose, app.repl != nil, output)
+}
+
+// pagedOutput returns the output used to write the response, paging it when
+// the pager is enabled.
+func (app *Application) pagedOutput() io.WriteCloser {
+	if app.config.NoPager {
+		return nopWriteCloser{app.output}
+	}
+	return newPagerWriter(app.output, os.Stdin)
+}
+
+// handleFilteredRequest performs the request applying the JMESPath filter to
+// the response before formatting it
+func (app *Application) handleFilteredRequest(input *cli.InputParser, filter string) error {
+	if filter == "" {
+		return app.HandleRequest(input)
+	}
+
+	opts, err := app.config.formatOptions()
+	if err != nil {
+		return err
+	}
+	opts.Query = filter
+
+	formatter, err := cli.NewFormatter(opts)
+	if err != nil {
+		return err
+	}
+
+	_, err = app.handleRequestWith(input, formatter)
+	return err
 }
 
 func (app *Application) initInteractive() {
 	app.repl, _ = readline.NewEx(
 		&readline.Config{
-			Prompt:          app.getClusterPrompt(),
-			InterruptPrompt: "^C",
-			EOFPrompt:       "exit",
-			AutoComplete:    cli.Completer,
-			HistoryFile:     "/tmp/elasticsearch-cli.history",
+			Prompt:                 app.getClusterPrompt(),
+			InterruptPrompt:        "^C",
+			EOFPrompt:              "exit",
+			AutoComplete:           cli.Completer,
+			DisableAutoSaveHistory: true,
 		},
 	)
-	go app.refreshCompleter()
+	app.loadHistory()
+	go app.refreshCompleter(app.indexChannel)
 	go app.poller.Start()
 }
 
 func (app *Application) getClusterPrompt() string {
-	res, err := app.client.HandleCall("GET", "/_cluster/health", "")
+	res, err := app.client.HandleCall("GET", "/_cluster/health", nil, nil)
 	if err != nil {
-		return DefaultPrompt
+		return app.prompt(defaultColor)
 	}
 
 	var clusterHealth elasticsearch.Health
 	err = json.NewDecoder(res.Body).Decode(&clusterHealth)
 	if err != nil {
-		return DefaultPrompt
+		return app.prompt(defaultColor)
+	}
+
+	if color, ok := clusterColors[clusterHealth.Status]; ok {
+		return app.prompt(color)
 	}
+	return app.prompt(defaultColor)
+}
 
-	return clusterPrompts[clusterHealth.Status]
+// prompt returns the prompt in the color, showing the cluster name when set
+func (app *Application) prompt(color string) string {
+	if app.config.Cluster == "" {
+		return color + "elasticsearch> " + resetColor
+	}
+	return fmt.Sprintf("%selasticsearch (%s)> %s", color, app.config.Cluster, resetColor)
 }
 
-func (app *Application) refreshCompleter() {
+func (app *Application) refreshCompleter(indexChannel chan []string) {
 	for {
 		select {
-		case indices, ok := <-app.indexChannel:
+		case indices, ok := <-indexChannel:
 			if !ok {
 				return
 			}
@@ -155,44 +296,159 @@ func (app *Application) refreshCompleter() {
 // Interactive runs the application like a readline / REPL
 func (app *Application) Interactive() error {
 	app.initInteractive()
-	defer app.poller.Stop()
+	// The poller is replaced when switching clusters
+	defer func() { app.poller.Stop() }()
+
 	for {
-		app.repl.Config.Prompt = app.getClusterPrompt()
+		if app.console.Open() {
+			app.repl.Config.Prompt = continuationPrompt
+		} else {
+			app.repl.Config.Prompt = app.getClusterPrompt()
+		}
 		line, err := app.repl.Readline()
 		if err == readline.ErrInterrupt {
+			if app.console.Open() {
+				app.console.Reset()
+				continue
+			}
 			if len(line) == 0 {
 				break
 			} else {
 				continue
 			}
 		} else if err == io.EOF {
+			app.sendRequests()
 			break
 		}
 
-		if len(line) == 0 {
-			continue
+		if !app.console.Open() && strings.HasPrefix(strings.TrimSpace(line), "!") {
+			if line, err = app.expandHistory(line); err != nil {
+				log.Print("[ERROR]: ", err)
+				continue
+			}
+			fmt.Fprintln(app.output, line)
 		}
+		app.saveHistory(line)
 
-		cleanLine := strings.TrimSpace(line)
-		if cleanLine == "exit" || cleanLine == "quit" {
+		if exit := app.handleLine(line); exit {
 			break
 		}
+	}
+
+	return app.repl.Close()
+}
+
+// interactiveCommands are the interactive mode commands, which aren't requests
+var interactiveCommands = []string{"set", "unset", "use", "history", "exit", "quit"}
 
-		input := strings.Fields(cleanLine)
-		if input[0] == "set" {
+// handleLine handles a line of interactive input, returning true when the
+// user exits. Like in the Kibana Dev Tools console, the request is kept open
+// after its request line so the body can be written (or pasted) over multiple
+// lines, and it's sent once an empty line, the next request or a command is
+// entered.
+func (app *Application) handleLine(line string) bool {
+	if !app.console.Pending() {
+		var cleanLine = strings.TrimSpace(line)
+		var input = strings.Fields(cleanLine)
+		if len(input) == 0 || utils.StringInSlice(input[0], interactiveCommands) || cli.IsRequestLine(cleanLine) {
+			app.sendRequests()
+		}
+
+		if len(input) == 0 {
+			return false
+		}
+
+		switch input[0] {
+		case "exit", "quit":
+			return len(input) == 1
+		case "set":
 			app.doSetCommands(input)
-			continue
+			return false
+		case "unset":
+			app.doUnsetCommands(input)
+			return false
+		case "use":
+			app.doUseCommand(input)
+			return false
+		case "history":
+			app.doHistoryCommand(input)
+			return false
+		}
+
+		if cli.IsRequestLine(cleanLine) {
+			line, app.filter = cli.SplitFilter(line)
+			if args, file := cli.SplitRedirect(strings.Fields(line)); file != "" {
+				if err := app.handleRedirect(args, file, app.filter); err != nil {
+					log.Print("[ERROR]: ", err)
+				}
+				return false
+			}
 		}
+	}
+
+	if err := app.console.ParseLine(line); err != nil {
+		app.console.Reset()
+		log.Print("[ERROR]: ", err)
+	}
+	return false
+}
+
+// sendRequests sends the requests which have been typed in interactive mode
+func (app *Application) sendRequests() {
+	if !app.console.Open() {
+		return
+	}
+
+	requests, err := app.console.Requests()
+	if err != nil {
+		log.Print("[ERROR]: ", err)
+		return
+	}
 
-		if err := app.HandleCli(input); err != nil {
+	for _, input := range requests {
+		if err := app.handleFilteredRequest(input, app.filter); err != nil {
 			log.Print("[ERROR]: ", err)
 		}
 	}
+}
 
-	return app.repl.Close()
+// handleRedirect performs the request streaming its body from the file
+func (app *Application) handleRedirect(args []string, file, filter string) error {
+	input, err := cli.NewInputParser(args)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	input.Body = f
+	return app.handleFilteredRequest(input, filter)
 }
 
 func (app *Application) doSetCommands(input []string) {
+	// The password is always prompted for, so it's never displayed or kept
+	// in the history
+	if len(input) >= 2 && input[1] == "pass" {
+		if len(input) > 2 {
+			log.Print("[ERROR]: ", errors.New("set pass doesn't take the password as an argument, it's prompted for"))
+			return
+		}
+		if err := app.setPassword(); err != nil {
+			log.Print("[ERROR]: ", err)
+		}
+		return
+	}
+
+	// The header values can contain spaces
+	if len(input) > 2 && input[1] == "header" {
+		app.setHeader(strings.Join(input[2:], " "))
+		return
+	}
+
 	if len(input) == 3 {
 		switch input[1] {
 		case "host":
@@ -209,8 +465,14 @@ func (app *Application) doSetCommands(input []string) {
 			}
 		case "user":
 			app.client.Config.User = input[2]
-		case "pass":
-			app.client.Config.Pass = input[2]
+		case "apikey":
+			app.client.Config.APIKey = input[2]
+		case "token":
+			app.client.Config.Token = input[2]
+		case "output":
+			app.setOutput(input[2])
+		case "pager":
+			app.setPager(input[2])
 		}
 	}
 
@@ -218,3 +480,98 @@ func (app *Application) doSetCommands(input []string) {
 		app.config.Verbose = true
 	}
 }
+
+// doUnsetCommands removes the settings modified with set
+func (app *Application) doUnsetCommands(input []string) {
+	if len(input) == 3 && input[1] == "header" {
+		app.client.Config.DelHeader(strings.TrimSuffix(input[2], ":"))
+	}
+}
+
+// doUseCommand switches to the cluster specified with "use <cluster>"
+func (app *Application) doUseCommand(input []string) {
+	if len(input) != 2 {
+		log.Print("[ERROR]: ", errors.New("usage: use <cluster>"))
+		return
+	}
+
+	if err := app.useCluster(input[1]); err != nil {
+		log.Print("[ERROR]: ", err)
+	}
+}
+
+// useCluster loads the cluster config, replacing the client, the formatter
+// and the index poller. The current cluster is kept when it fails.
+func (app *Application) useCluster(cluster string) error {
+	if app.config.LoadCluster == nil {
+		return errors.New("switching clusters is not supported")
+	}
+
+	config, err := app.config.LoadCluster(cluster)
+	if err != nil {
+		return err
+	}
+
+	httpClient, err := newClient(config)
+	if err != nil {
+		return err
+	}
+
+	formatter, err := newFormatter(config)
+	if err != nil {
+		return err
+	}
+
+	app.poller.Stop()
+	app.config, app.client, app.formatFunc = config, httpClient, formatter
+	app.indexChannel = make(chan []string, 1)
+	app.poller = poller.NewIndexPoller(httpClient, app.indexChannel, config.PollInterval)
+	if app.repl != nil {
+		app.loadHistory()
+		app.repl.Config.AutoComplete = cli.Completer
+		go app.refreshCompleter(app.indexChannel)
+		go app.poller.Start()
+	}
+	return nil
+}
+
+// setHeader adds or replaces a "Key: Value" header sent with the requests
+func (app *Application) setHeader(header string) {
+	key, value, err := ParseHeader(header)
+	if err != nil {
+		log.Print("[ERROR]: ", err)
+		return
+	}
+	app.client.Config.SetHeader(key, value)
+}
+
+// setPager enables or disables the pager
+func (app *Application) setPager(value string) {
+	switch value {
+	case "on":
+		app.config.NoPager = false
+	case "off":
+		app.config.NoPager = true
+	default:
+		log.Print("[ERROR]: ", fmt.Errorf("pager \"%s\" is not supported, must be one of on, off", value))
+	}
+}
+
+// setOutput changes the output format used to format the responses
+func (app *Application) setOutput(output string) {
+	opts, err := app.config.formatOptions()
+	if err != nil {
+		log.Print("[ERROR]: ", err)
+		return
+	}
+	opts.Output = output
+
+	formatter, err := cli.NewFormatter(opts)
+	if err != nil {
+		log.Print("[ERROR]: ", err)
+		return
+	}
+
+	app.config.Output = output
+	app.formatFunc = formatter
+}
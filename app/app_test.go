@@ -3,10 +3,13 @@ package app
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/chzyer/readline"
@@ -15,9 +18,14 @@ import (
 	"github.com/marclop/elasticsearch-cli/poller"
 )
 
-var defaultConfig = func(c *client.Config, _ error) *client.Config { return c }(
-	client.NewClientConfig("http://localhost", 9200, "user", "pass", 10, false),
-)
+var defaultConfig = newTestClientConfig()
+
+// newTestClientConfig returns a client config which isn't shared with the
+// other tests
+func newTestClientConfig() *client.Config {
+	c, _ := client.NewClientConfig("http://localhost", 9200, "user", "pass", 10, client.TLSConfig{})
+	return c
+}
 
 func TestInitialize(t *testing.T) {
 	channel := make(chan []string, 1)
@@ -51,6 +59,7 @@ func TestInitialize(t *testing.T) {
 					PollInterval: 10,
 				},
 				client:       client.NewHTTP(defaultConfig, client.NewMock()),
+				console:      cli.NewConsoleParser(),
 				indexChannel: channel,
 				poller:       &poller.IndexPoller{},
 				formatFunc:   nil,
@@ -188,6 +197,102 @@ func TestApplication_HandleCli(t *testing.T) {
 	}
 }
 
+// requestRecorder records the requests it receives as "METHOD path body"
+type requestRecorder struct {
+	requests []string
+}
+
+func (r *requestRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body string
+	if req.Body != nil {
+		content, _ := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		body = string(content)
+	}
+	r.requests = append(r.requests, strings.TrimSpace(fmt.Sprint(req.Method, " ", req.URL.Path, " ", body)))
+
+	return &http.Response{
+		StatusCode: 200,
+		Status:     "200 OK",
+		Body:       ioutil.NopCloser(strings.NewReader("{}")),
+		Request:    req,
+	}, nil
+}
+
+func TestApplication_handleLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []string
+		want     []string
+		wantExit bool
+	}{
+		{
+			"RequestIsSentWithAnEmptyLine",
+			[]string{"GET /", ""},
+			[]string{"GET /"},
+			false,
+		},
+		{
+			"RequestIsNotSentUntilItEnds",
+			[]string{"GET /"},
+			nil,
+			false,
+		},
+		{
+			"PastedKibanaBlockIsSentWithItsBody",
+			[]string{"GET /idx/_search", "{", `  "query": {"match_all": {}}`, "}", ""},
+			[]string{"GET /idx/_search {\n  \"query\": {\"match_all\": {}}\n}"},
+			false,
+		},
+		{
+			"NDJSONBodyIsSentWithAllOfItsDocuments",
+			[]string{"POST /_bulk", `{"index": {"_index": "a"}}`, `{"f": 1}`, `{"index": {"_index": "a"}}`, `{"f": 2}`, ""},
+			[]string{"POST /_bulk {\"index\":{\"_index\":\"a\"}}\n{\"f\":1}\n{\"index\":{\"_index\":\"a\"}}\n{\"f\":2}"},
+			false,
+		},
+		{
+			"NextRequestLineSendsTheOpenRequest",
+			[]string{"GET /", "GET /_cat/indices", ""},
+			[]string{"GET /", "GET /_cat/indices"},
+			false,
+		},
+		{
+			"ExitSendsTheOpenRequest",
+			[]string{"DELETE /idx", "exit"},
+			[]string{"DELETE /idx"},
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var recorder = &requestRecorder{}
+			config, err := client.NewClientConfig("http://localhost", 9200, "", "", 10, client.TLSConfig{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			app := &Application{
+				config:     &Config{NoPager: true},
+				client:     client.NewHTTP(config, &http.Client{Transport: recorder}),
+				formatFunc: cli.Format,
+				output:     &bytes.Buffer{},
+				console:    cli.NewConsoleParser(),
+			}
+
+			var exit bool
+			for _, line := range tt.lines {
+				exit = app.handleLine(line)
+			}
+			if exit != tt.wantExit {
+				t.Errorf("Application.handleLine() = %v, want %v", exit, tt.wantExit)
+			}
+			if !reflect.DeepEqual(recorder.requests, tt.want) {
+				t.Errorf("Application.handleLine() requests = %q, want %q", recorder.requests, tt.want)
+			}
+		})
+	}
+}
+
 func TestApplication_doSetCommands(t *testing.T) {
 	type fields struct {
 		config       *Config
@@ -293,13 +398,13 @@ func TestApplication_doSetCommands(t *testing.T) {
 			client.NewHTTP(defaultConfig, client.NewMock()),
 		},
 		{
-			"password is modified",
+			"password is not modified from the arguments",
 			fields{
 				config: &Config{
 					Verbose:      false,
 					PollInterval: 10,
 				},
-				client: client.NewHTTP(defaultConfig, client.NewMock()),
+				client: client.NewHTTP(newTestClientConfig(), client.NewMock()),
 				repl:   &readline.Instance{},
 				format: cli.Format,
 				output: &bytes.Buffer{},
@@ -311,7 +416,7 @@ func TestApplication_doSetCommands(t *testing.T) {
 					"elastic",
 				},
 			},
-			client.NewHTTP(defaultConfig, client.NewMock()),
+			client.NewHTTP(newTestClientConfig(), client.NewMock()),
 		},
 		{
 			"Verbose is modified",
@@ -405,7 +510,7 @@ func TestApplication_getClusterPrompt(t *testing.T) {
 				formatFunc: cli.Format,
 				output:     &bytes.Buffer{},
 			},
-			GreenPrompt,
+			"\x1b[32melasticsearch> \x1b[0m",
 		},
 		{
 			"When the cluster is yellow, returns the yellowPrompt",
@@ -442,7 +547,7 @@ func TestApplication_getClusterPrompt(t *testing.T) {
 				formatFunc: cli.Format,
 				output:     &bytes.Buffer{},
 			},
-			YellowPrompt,
+			"\x1b[33melasticsearch> \x1b[0m",
 		},
 		{
 			"When the cluster is red, returns the redPrompt",
@@ -479,7 +584,7 @@ func TestApplication_getClusterPrompt(t *testing.T) {
 				formatFunc: cli.Format,
 				output:     &bytes.Buffer{},
 			},
-			RedPrompt,
+			"\x1b[31melasticsearch> \x1b[0m",
 		},
 		{
 			"When the request returns an unparsable body, returns the defaultPrompt",
@@ -500,7 +605,7 @@ func TestApplication_getClusterPrompt(t *testing.T) {
 				formatFunc: cli.Format,
 				output:     &bytes.Buffer{},
 			},
-			DefaultPrompt,
+			"\x1b[34melasticsearch> \x1b[0m",
 		},
 		{
 			"When the request returns an error, returns the defaultPrompt",
@@ -516,7 +621,28 @@ func TestApplication_getClusterPrompt(t *testing.T) {
 				formatFunc: cli.Format,
 				output:     &bytes.Buffer{},
 			},
-			DefaultPrompt,
+			"\x1b[34melasticsearch> \x1b[0m",
+		},
+		{
+			"When the cluster name is set, returns the prompt with the cluster name",
+			fields{
+				config: &Config{
+					Cluster:      "prod",
+					PollInterval: 10,
+				},
+				client: client.NewHTTP(defaultConfig, client.NewMock(
+					client.MockResponse{Response: http.Response{
+						StatusCode: 200,
+						Request:    &http.Request{Method: "GET"},
+						Body:       client.NewStringBody(`{"cluster_name": "myCluster", "status": "yellow"}`),
+						Header:     http.Header{"Content-Type": []string{"application/json"}},
+					}},
+				)),
+				repl:       &readline.Instance{},
+				formatFunc: cli.Format,
+				output:     &bytes.Buffer{},
+			},
+			"\x1b[33melasticsearch (prod)> \x1b[0m",
 		},
 	}
 	for _, tt := range tests {
@@ -537,3 +663,242 @@ func TestApplication_getClusterPrompt(t *testing.T) {
 		})
 	}
 }
+
+func TestApplication_setOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{
+			"output is modified",
+			"yaml",
+			"yaml",
+		},
+		{
+			"output is not modified due invalid format",
+			"xml",
+			"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &Application{
+				config:     &Config{},
+				client:     client.NewHTTP(defaultConfig, client.NewMock()),
+				formatFunc: cli.Format,
+				output:     &bytes.Buffer{},
+			}
+			app.doSetCommands([]string{"set", "output", tt.output})
+			if app.config.Output != tt.want {
+				t.Errorf("app.config.Output = %v, want %v", app.config.Output, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplication_setPager(t *testing.T) {
+	tests := []struct {
+		name    string
+		noPager bool
+		value   string
+		want    bool
+	}{
+		{
+			"pager is disabled",
+			false,
+			"off",
+			true,
+		},
+		{
+			"pager is enabled",
+			true,
+			"on",
+			false,
+		},
+		{
+			"pager is not modified due invalid value",
+			true,
+			"maybe",
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &Application{
+				config: &Config{NoPager: tt.noPager},
+				client: client.NewHTTP(defaultConfig, client.NewMock()),
+				output: &bytes.Buffer{},
+			}
+			app.doSetCommands([]string{"set", "pager", tt.value})
+			if app.config.NoPager != tt.want {
+				t.Errorf("app.config.NoPager = %v, want %v", app.config.NoPager, tt.want)
+			}
+		})
+	}
+}
+
+// headerRecorder records the headers of the last request
+type headerRecorder struct {
+	header http.Header
+}
+
+func (r *headerRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.header = req.Header
+	return &http.Response{StatusCode: 200, Body: http.NoBody, Request: req}, nil
+}
+
+func TestApplication_setHeader(t *testing.T) {
+	tests := []struct {
+		name     string
+		commands [][]string
+		want     http.Header
+	}{
+		{
+			"header is set",
+			[][]string{{"set", "header", "X-Opaque-Id:", "my", "request"}},
+			http.Header{"Content-Type": {"application/json"}, "X-Opaque-Id": {"my request"}},
+		},
+		{
+			"header is replaced",
+			[][]string{{"set", "header", "content-type:application/x-ndjson"}},
+			http.Header{"Content-Type": {"application/x-ndjson"}},
+		},
+		{
+			"header is unset",
+			[][]string{{"set", "header", "X-Opaque-Id:", "my-request"}, {"unset", "header", "x-opaque-id"}},
+			http.Header{"Content-Type": {"application/json"}},
+		},
+		{
+			"header is not set due invalid value",
+			[][]string{{"set", "header", "X-Opaque-Id"}},
+			http.Header{"Content-Type": {"application/json"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := client.NewClientConfig("http://localhost", 9200, "", "", 10, client.TLSConfig{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var recorder = new(headerRecorder)
+			app := &Application{
+				config: &Config{},
+				client: client.NewHTTP(config, &http.Client{Transport: recorder}),
+				output: &bytes.Buffer{},
+			}
+			for _, command := range tt.commands {
+				if command[0] == "unset" {
+					app.doUnsetCommands(command)
+					continue
+				}
+				app.doSetCommands(command)
+			}
+
+			if _, err := app.client.HandleCall("GET", "/", nil, nil); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(recorder.header, tt.want) {
+				t.Errorf("request headers = %v, want %v", recorder.header, tt.want)
+			}
+		})
+	}
+}
+
+// stoppedPoller records whether it has been stopped
+type stoppedPoller struct {
+	stopped bool
+}
+
+func (p *stoppedPoller) Start() {}
+func (p *stoppedPoller) Stop()  { p.stopped = true }
+
+func TestApplication_useCluster(t *testing.T) {
+	var staging = &Config{
+		Cluster: "staging",
+		Host:    "http://staging:9201",
+		User:    "marc",
+		Pass:    "secret",
+		Output:  "yaml",
+		Client:  client.NewMock(),
+	}
+	tests := []struct {
+		name        string
+		loadCluster func(string) (*Config, error)
+		wantErr     bool
+		wantCluster string
+		wantHost    string
+		wantStopped bool
+	}{
+		{
+			"useCluster replaces the client and the poller",
+			func(cluster string) (*Config, error) {
+				if cluster != "staging" {
+					return nil, errors.New("cluster doesn't exist")
+				}
+				return staging, nil
+			},
+			false,
+			"staging",
+			"http://staging:9201",
+			true,
+		},
+		{
+			"useCluster keeps the current cluster when loading fails",
+			func(cluster string) (*Config, error) { return nil, errors.New("cluster doesn't exist") },
+			true,
+			"default",
+			"http://localhost:9200",
+			false,
+		},
+		{
+			"useCluster keeps the current cluster with an invalid config",
+			func(cluster string) (*Config, error) { return &Config{Host: "ftp://staging"}, nil },
+			true,
+			"default",
+			"http://localhost:9200",
+			false,
+		},
+		{
+			"useCluster fails when switching clusters is not supported",
+			nil,
+			true,
+			"default",
+			"http://localhost:9200",
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := client.NewClientConfig("http://localhost", 9200, "", "", 10, client.TLSConfig{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var indexPoller = &stoppedPoller{}
+			app := &Application{
+				config: &Config{Cluster: "default", LoadCluster: tt.loadCluster},
+				client: client.NewHTTP(config, client.NewMock()),
+				poller: indexPoller,
+				output: &bytes.Buffer{},
+			}
+
+			if err := app.useCluster("staging"); (err != nil) != tt.wantErr {
+				t.Errorf("Application.useCluster() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if app.config.Cluster != tt.wantCluster {
+				t.Errorf("Application.useCluster() cluster = %v, want %v", app.config.Cluster, tt.wantCluster)
+			}
+			if host := app.client.Config.HTTPAdress(); host != tt.wantHost {
+				t.Errorf("Application.useCluster() host = %v, want %v", host, tt.wantHost)
+			}
+			if indexPoller.stopped != tt.wantStopped {
+				t.Errorf("Application.useCluster() stopped the poller = %v, want %v", indexPoller.stopped, tt.wantStopped)
+			}
+			if tt.wantStopped && app.poller == indexPoller {
+				t.Error("Application.useCluster() didn't replace the poller")
+			}
+		})
+	}
+}
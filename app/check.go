@@ -0,0 +1,46 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/marclop/elasticsearch-cli/elasticsearch"
+)
+
+// Check tests the connectivity and the credentials by calling the root and
+// cluster health endpoints, writing a summary of the cluster to w
+func (app *Application) Check(w io.Writer) error {
+	var info elasticsearch.Info
+	if err := app.getJSON("/", &info); err != nil {
+		return err
+	}
+
+	var health elasticsearch.Health
+	if err := app.getJSON("/_cluster/health", &health); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w, "Cluster:     ", app.config.Cluster)
+	fmt.Fprintln(w, "URL:         ", app.client.Config.HTTPAdress())
+	fmt.Fprintln(w, "Name:        ", info.ClusterName)
+	fmt.Fprintln(w, "Version:     ", info.Version.Number)
+	fmt.Fprintln(w, "Health:      ", health.Status)
+	fmt.Fprintln(w, "Nodes:       ", health.NumberOfNodes)
+	return nil
+}
+
+// getJSON decodes the JSON response of the GET request into v, failing when
+// the response status isn't successful
+func (app *Application) getJSON(path string, v interface{}) error {
+	res, err := app.client.HandleCall("GET", path, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("GET %s failed with \"%s\"", path, res.Status)
+	}
+	return json.NewDecoder(res.Body).Decode(v)
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/marclop/elasticsearch-cli/client"
+)
+
+func TestApplication_Check(t *testing.T) {
+	tests := []struct {
+		name    string
+		caller  *http.Client
+		want    string
+		wantErr string
+	}{
+		{
+			"CheckSucceeds",
+			client.NewMock(
+				client.MockResponse{Response: http.Response{
+					StatusCode: 200,
+					Body:       client.NewStringBody(`{"name":"node-1","cluster_name":"logs","version":{"number":"6.8.0"}}`),
+				}},
+				client.MockResponse{Response: http.Response{
+					StatusCode: 200,
+					Body:       client.NewStringBody(`{"cluster_name":"logs","status":"yellow","number_of_nodes":3}`),
+				}},
+			),
+			`Cluster:      prod
+URL:          http://localhost:9200
+Name:         logs
+Version:      6.8.0
+Health:       yellow
+Nodes:        3
+`,
+			"",
+		},
+		{
+			"CheckFailsWithUnauthorized",
+			client.NewMock(
+				client.MockResponse{Response: http.Response{
+					StatusCode: 401,
+					Status:     "401 Unauthorized",
+					Body:       client.NewStringBody(`{}`),
+				}},
+			),
+			"",
+			`GET / failed with "401 Unauthorized"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := client.NewClientConfig("http://localhost", 9200, "", "", 10, client.TLSConfig{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			app := &Application{
+				config: &Config{Cluster: "prod"},
+				client: client.NewHTTP(config, tt.caller),
+			}
+
+			var output bytes.Buffer
+			err = app.Check(&output)
+			if (err != nil && err.Error() != tt.wantErr) || (err == nil && tt.wantErr != "") {
+				t.Errorf("Application.Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if output.String() != tt.want {
+				t.Errorf("Application.Check() = %v, want %v", output.String(), tt.want)
+			}
+		})
+	}
+}
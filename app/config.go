@@ -1,19 +1,262 @@
 package app
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/chzyer/readline"
+	"github.com/marclop/elasticsearch-cli/cli"
+	"github.com/marclop/elasticsearch-cli/client"
+	"github.com/marclop/elasticsearch-cli/credentials"
+)
+
+const (
+	// ColorAuto colourises the output only when it's a terminal
+	ColorAuto = "auto"
+	// ColorAlways colourises the output
+	ColorAlways = "always"
+	// ColorNever disables the output colours
+	ColorNever = "never"
 )
 
 // Config for elasticsearch-cli Application
 type Config struct {
-	User         string `mapstructure:"user"`
-	Pass         string `mapstructure:"pass"`
-	Host         string `mapstructure:"host"`
-	Port         int    `mapstructure:"port"`
-	Verbose      bool   `mapstructure:"verbose"`
-	PollInterval int    `mapstructure:"poll-interval"`
-	Timeout      int    `mapstructure:"timeout"`
-	Insecure     bool   `mapstructure:"insecure"`
-	Headers      map[string]string
-	Client       *http.Client
+	Cluster            string            `mapstructure:"cluster"`
+	User               string            `mapstructure:"user"`
+	Pass               string            `mapstructure:"pass"`
+	PassCommand        string            `mapstructure:"pass-command"`
+	PassFile           string            `mapstructure:"pass-file"`
+	APIKey             string            `mapstructure:"api-key"`
+	Token              string            `mapstructure:"token"`
+	Host               string            `mapstructure:"host"`
+	Port               int               `mapstructure:"port"`
+	Hosts              []string          `mapstructure:"hosts"`
+	Sniff              bool              `mapstructure:"sniff"`
+	CloudID            string            `mapstructure:"cloud-id"`
+	CloudAuth          string            `mapstructure:"cloud-auth"`
+	Verbose            bool              `mapstructure:"verbose"`
+	PollInterval       int               `mapstructure:"poll-interval"`
+	Timeout            int               `mapstructure:"timeout"`
+	Retries            int               `mapstructure:"retries"`
+	RetryBackoff       time.Duration     `mapstructure:"retry-backoff"`
+	RetryMaxBackoff    time.Duration     `mapstructure:"retry-max-backoff"`
+	RetryNonIdempotent bool              `mapstructure:"retry-non-idempotent"`
+	Compress           bool              `mapstructure:"compress"`
+	Insecure           bool              `mapstructure:"insecure"`
+	Proxy              string            `mapstructure:"proxy"`
+	NoProxy            []string          `mapstructure:"no-proxy"`
+	CACert             string            `mapstructure:"ca-cert"`
+	ClientCert         string            `mapstructure:"client-cert"`
+	ClientKey          string            `mapstructure:"client-key"`
+	ServerName         string            `mapstructure:"tls-server-name"`
+	Region             string            `mapstructure:"region"`
+	AWSSigV4           bool              `mapstructure:"aws-sigv4"`
+	AWSService         string            `mapstructure:"aws-service"`
+	AWSProfile         string            `mapstructure:"aws-profile"`
+	Output             string            `mapstructure:"output"`
+	Columns            []string          `mapstructure:"columns"`
+	Query              string            `mapstructure:"query"`
+	Color              string            `mapstructure:"color"`
+	Theme              map[string]string `mapstructure:"theme"`
+	NoPager            bool              `mapstructure:"no-pager"`
+	Headers            map[string]string `mapstructure:"headers"`
+	Header             []string          `mapstructure:"header"`
+	HistorySize        int               `mapstructure:"history-size"`
+	HistoryFile        string
+	Client             *http.Client
+	CredentialStore    *credentials.Store
+	PasswordPrompt     func(prompt string) (string, error)
+	LoadCluster        func(cluster string) (*Config, error)
+}
+
+// endpoints returns the Elasticsearch hosts and port, decoded from the Cloud
+// ID when it's specified. The hosts take precedence over the single host.
+func (c *Config) endpoints() ([]string, int, error) {
+	if c.CloudID != "" {
+		host, port, err := client.ParseCloudID(c.CloudID)
+		if err != nil {
+			return nil, 0, err
+		}
+		return []string{host}, port, nil
+	}
+
+	if hosts := splitList(c.Hosts); len(hosts) > 0 {
+		return hosts, c.Port, nil
+	}
+	return []string{c.Host}, c.Port, nil
+}
+
+// splitList splits the comma separated values, which is how the list settings
+// are read from the environment variables (i.e. ES_HOSTS), since they aren't
+// split like the flags are
+func splitList(values []string) []string {
+	var list []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+	return list
+}
+
+// credentials returns the basic authentication user and password, the Cloud
+// credentials take precedence when specified
+func (c *Config) credentials() (string, string, error) {
+	if c.CloudAuth != "" {
+		return client.ParseCloudAuth(c.CloudAuth)
+	}
+
+	pass, err := c.password()
+	if err != nil {
+		return "", "", err
+	}
+	return c.User, pass, nil
+}
+
+// Validate checks the settings without resolving the password, loading the
+// AWS credentials or connecting to the cluster
+func (c *Config) Validate() error {
+	hosts, port, err := c.endpoints()
+	if err != nil {
+		return err
+	}
+
+	for _, host := range hosts {
+		clientConfig, err := client.NewClientConfig(host, port, "", "", c.Timeout, c.tlsConfig())
+		if err != nil {
+			return err
+		}
+
+		if c.Proxy != "" {
+			if err := clientConfig.SetProxy(c.Proxy, c.NoProxy); err != nil {
+				return err
+			}
+		}
+	}
+
+	if c.CloudAuth != "" {
+		if _, _, err := client.ParseCloudAuth(c.CloudAuth); err != nil {
+			return err
+		}
+	}
+
+	if c.AWSSigV4 && c.Region == "" {
+		return errors.New("region is required to sign the requests with AWS SigV4")
+	}
+
+	if _, err := c.headers(); err != nil {
+		return err
+	}
+
+	opts, err := c.formatOptions()
+	if err != nil {
+		return err
+	}
+
+	_, err = cli.NewFormatter(opts)
+	return err
+}
+
+// retryConfig returns the client.RetryConfig specified in the Config
+func (c *Config) retryConfig() client.RetryConfig {
+	return client.RetryConfig{
+		Retries:       c.Retries,
+		Backoff:       c.RetryBackoff,
+		MaxBackoff:    c.RetryMaxBackoff,
+		NonIdempotent: c.RetryNonIdempotent,
+	}
+}
+
+// tlsConfig returns the client.TLSConfig specified in the Config
+func (c *Config) tlsConfig() client.TLSConfig {
+	return client.TLSConfig{
+		Insecure:   c.Insecure,
+		CACert:     c.CACert,
+		ClientCert: c.ClientCert,
+		ClientKey:  c.ClientKey,
+		ServerName: c.ServerName,
+	}
+}
+
+// signer returns the AWS SigV4 request signer when it's enabled, nil otherwise
+func (c *Config) signer() (client.Signer, error) {
+	if !c.AWSSigV4 {
+		return nil, nil
+	}
+
+	signer, err := client.NewSigV4Signer(c.Region, c.AWSService, c.AWSProfile)
+	if err != nil {
+		return nil, err
+	}
+	return signer, nil
+}
+
+// headers returns the custom request headers, the "Key: Value" Header entries
+// (set with --header) take precedence over the Headers map
+func (c *Config) headers() (map[string]string, error) {
+	// The keys are canonicalized so the flags replace the config headers
+	// regardless of their case, viper lowercases the config file keys
+	var headers = make(map[string]string, len(c.Headers)+len(c.Header))
+	for key, value := range c.Headers {
+		headers[http.CanonicalHeaderKey(key)] = value
+	}
+
+	for _, header := range c.Header {
+		key, value, err := ParseHeader(header)
+		if err != nil {
+			return nil, err
+		}
+		headers[http.CanonicalHeaderKey(key)] = value
+	}
+	return headers, nil
+}
+
+// ParseHeader parses a "Key: Value" header
+func ParseHeader(header string) (string, string, error) {
+	var parts = strings.SplitN(header, ":", 2)
+	var key = strings.TrimSpace(parts[0])
+	if len(parts) != 2 || key == "" || strings.ContainsAny(key, " \t") {
+		return "", "", fmt.Errorf("header \"%s\" is invalid, must be \"Key: Value\"", header)
+	}
+	return key, strings.TrimSpace(parts[1]), nil
+}
+
+// formatOptions returns the cli.FormatOptions specified in the Config
+func (c *Config) formatOptions() (cli.FormatOptions, error) {
+	color, err := c.colorEnabled()
+	if err != nil {
+		return cli.FormatOptions{}, err
+	}
+
+	theme, err := cli.NewTheme(c.Theme)
+	if err != nil {
+		return cli.FormatOptions{}, err
+	}
+
+	return cli.FormatOptions{
+		Output:  c.Output,
+		Columns: splitList(c.Columns),
+		Query:   c.Query,
+		Color:   color,
+		Theme:   theme,
+	}, nil
+}
+
+// colorEnabled returns true when the output needs to be colourised
+func (c *Config) colorEnabled() (bool, error) {
+	switch c.Color {
+	case ColorAlways:
+		return true, nil
+	case ColorNever:
+		return false, nil
+	case ColorAuto, "":
+		return readline.IsTerminal(int(os.Stdout.Fd())), nil
+	}
+	return false, fmt.Errorf("color \"%s\" is not supported, must be one of %s, %s, %s", c.Color, ColorAuto, ColorAlways, ColorNever)
 }
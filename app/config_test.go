@@ -0,0 +1,282 @@
+package app
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestConfig_endpoints(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    *Config
+		wantHosts []string
+		wantPort  int
+		wantErr   bool
+	}{
+		{
+			"endpointsReturnsHostAndPort",
+			&Config{Host: "http://localhost", Port: 9200},
+			[]string{"http://localhost"},
+			9200,
+			false,
+		},
+		{
+			"endpointsPrefersHosts",
+			&Config{Host: "http://localhost", Port: 9200, Hosts: []string{"http://node1", "http://node2:9201"}},
+			[]string{"http://node1", "http://node2:9201"},
+			9200,
+			false,
+		},
+		{
+			"endpointsSplitsCommaSeparatedHosts",
+			&Config{Host: "http://localhost", Port: 9200, Hosts: []string{"http://node1, http://node2:9201", "http://node3"}},
+			[]string{"http://node1", "http://node2:9201", "http://node3"},
+			9200,
+			false,
+		},
+		{
+			"endpointsDecodesCloudID",
+			&Config{
+				Host:    "http://localhost",
+				Port:    9200,
+				CloudID: "my-deployment:dXMtZWFzdC0xLmF3cy5mb3VuZC5pbyRjZWM2ZjI2MWE3NGJmMjRjZTMzYmI4ODExYjg0Mjk0ZiRjNmMyY2E2ZDA0MjI0OWFmMGNjN2Q3YTllOTYyNTc0Mw==",
+			},
+			[]string{"https://cec6f261a74bf24ce33bb8811b84294f.us-east-1.aws.found.io"},
+			443,
+			false,
+		},
+		{
+			"endpointsFailsWithInvalidCloudID",
+			&Config{CloudID: "my-deployment:invalid"},
+			nil,
+			0,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hosts, port, err := tt.config.endpoints()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Config.endpoints() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(hosts, tt.wantHosts) || port != tt.wantPort {
+				t.Errorf("Config.endpoints() = %v, %v, want %v, %v", hosts, port, tt.wantHosts, tt.wantPort)
+			}
+		})
+	}
+}
+
+func TestConfig_listsFromEnv(t *testing.T) {
+	defer os.Unsetenv("ES_HOSTS")
+	defer os.Unsetenv("ES_COLUMNS")
+	os.Setenv("ES_HOSTS", "http://node1:9200,http://node2:9200")
+	os.Setenv("ES_COLUMNS", "index,health")
+
+	var v = viper.New()
+	v.SetEnvPrefix("ES")
+	v.BindEnv("hosts")
+	v.BindEnv("columns")
+
+	var c Config
+	if err := v.Unmarshal(&c); err != nil {
+		t.Fatal(err)
+	}
+
+	var wantHosts = []string{"http://node1:9200", "http://node2:9200"}
+	if hosts, _, err := c.endpoints(); err != nil || !reflect.DeepEqual(hosts, wantHosts) {
+		t.Errorf("Config.endpoints() = %v, %v, want %v", hosts, err, wantHosts)
+	}
+	if err := c.Validate(); err != nil {
+		t.Errorf("Config.Validate() error = %v", err)
+	}
+
+	var wantColumns = []string{"index", "health"}
+	if opts, err := c.formatOptions(); err != nil || !reflect.DeepEqual(opts.Columns, wantColumns) {
+		t.Errorf("Config.formatOptions() columns = %v, %v, want %v", opts.Columns, err, wantColumns)
+	}
+}
+
+func TestConfig_credentials(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *Config
+		wantUser string
+		wantPass string
+		wantErr  bool
+	}{
+		{
+			"credentialsReturnsUserAndPass",
+			&Config{User: "marc", Pass: "mypass"},
+			"marc",
+			"mypass",
+			false,
+		},
+		{
+			"credentialsPrefersCloudAuth",
+			&Config{User: "marc", Pass: "mypass", CloudAuth: "elastic:changeme"},
+			"elastic",
+			"changeme",
+			false,
+		},
+		{
+			"credentialsFailsWithInvalidCloudAuth",
+			&Config{CloudAuth: "elastic"},
+			"",
+			"",
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, pass, err := tt.config.credentials()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Config.credentials() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if user != tt.wantUser || pass != tt.wantPass {
+				t.Errorf("Config.credentials() = %v, %v, want %v, %v", user, pass, tt.wantUser, tt.wantPass)
+			}
+		})
+	}
+}
+
+func TestConfig_signer(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     *Config
+		wantSigner bool
+		wantErr    bool
+	}{
+		{
+			"signerIsDisabledByDefault",
+			&Config{Region: "us-east-1"},
+			false,
+			false,
+		},
+		{
+			"signerRequiresRegion",
+			&Config{AWSSigV4: true},
+			false,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.config.signer()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Config.signer() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if (got != nil) != tt.wantSigner {
+				t.Errorf("Config.signer() = %v, wantSigner %v", got, tt.wantSigner)
+			}
+		})
+	}
+}
+
+func TestConfig_headers(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *Config
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			"headersMergesTheFlagsAndConfigHeaders",
+			&Config{
+				Headers: map[string]string{"x-opaque-id": "config", "x-found-cluster": "abc"},
+				Header:  []string{"x-opaque-id: flag", "Accept:application/json"},
+			},
+			map[string]string{"X-Opaque-Id": "flag", "X-Found-Cluster": "abc", "Accept": "application/json"},
+			false,
+		},
+		{
+			"headersFlagsReplaceConfigHeadersWithDifferentCase",
+			&Config{
+				Headers: map[string]string{"x-opaque-id": "config"},
+				Header:  []string{"X-OPAQUE-ID: flag"},
+			},
+			map[string]string{"X-Opaque-Id": "flag"},
+			false,
+		},
+		{
+			"headersFailsWithInvalidHeader",
+			&Config{Header: []string{"X-Opaque-Id"}},
+			nil,
+			true,
+		},
+		{
+			"headersFailsWithEmptyKey",
+			&Config{Header: []string{": value"}},
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.config.headers()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Config.headers() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Config.headers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *Config
+		wantErr bool
+	}{
+		{
+			"ValidateSucceeds",
+			&Config{Host: "https://localhost:9243", Proxy: "socks5://bastion:1080", Output: "json", Color: ColorNever},
+			false,
+		},
+		{
+			"ValidateDoesNotResolveThePassword",
+			&Config{Host: "http://localhost", User: "marc", PassCommand: "exit 1", Output: "json", Color: ColorNever},
+			false,
+		},
+		{
+			"ValidateFailsWithInvalidHost",
+			&Config{Hosts: []string{"http://localhost", "ftp://localhost"}, Output: "json", Color: ColorNever},
+			true,
+		},
+		{
+			"ValidateFailsWithInvalidProxy",
+			&Config{Host: "http://localhost", Proxy: "ftp://proxy", Output: "json", Color: ColorNever},
+			true,
+		},
+		{
+			"ValidateFailsWithMissingCACert",
+			&Config{Host: "https://localhost", CACert: "/nonexistent/ca.pem", Output: "json", Color: ColorNever},
+			true,
+		},
+		{
+			"ValidateFailsWithInvalidOutput",
+			&Config{Host: "http://localhost", Output: "xml", Color: ColorNever},
+			true,
+		},
+		{
+			"ValidateFailsWithoutAWSRegion",
+			&Config{Host: "http://localhost", AWSSigV4: true, Output: "json", Color: ColorNever},
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Config.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
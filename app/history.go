@@ -0,0 +1,73 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/marclop/elasticsearch-cli/history"
+)
+
+// loadHistory replaces the REPL history with the history of the cluster
+func (app *Application) loadHistory() {
+	app.history = nil
+	app.repl.ResetHistory()
+	app.repl.Config.HistoryLimit = app.config.HistorySize
+	if app.config.HistorySize <= 0 {
+		// Disables the readline history
+		app.repl.Config.HistoryLimit = -1
+		return
+	}
+
+	h, err := history.Open(app.config.HistoryFile, app.config.HistorySize)
+	if err != nil {
+		log.Print("[ERROR]: ", err)
+		return
+	}
+
+	app.history = h
+	for _, line := range h.Lines() {
+		app.repl.SaveHistory(line)
+	}
+}
+
+// saveHistory stores the line in the history, unless it contains credentials
+func (app *Application) saveHistory(line string) {
+	if app.history == nil || strings.TrimSpace(line) == "" || history.Sensitive(line) {
+		return
+	}
+
+	if err := app.history.Add(line); err != nil {
+		log.Print("[ERROR]: ", err)
+	}
+	app.repl.SaveHistory(line)
+}
+
+// expandHistory returns the history line referenced by "!<number>"
+func (app *Application) expandHistory(line string) (string, error) {
+	if app.history == nil {
+		return "", errors.New("the history is disabled")
+	}
+
+	var reference = strings.TrimPrefix(strings.TrimSpace(line), "!")
+	number, err := strconv.Atoi(reference)
+	if err != nil {
+		return "", fmt.Errorf("history entry \"%s\" is invalid, must be !<number>", reference)
+	}
+	return app.history.Get(number)
+}
+
+// doHistoryCommand prints the numbered history lines, only the ones which
+// contain the term with "history <term>"
+func (app *Application) doHistoryCommand(input []string) {
+	if app.history == nil {
+		log.Print("[ERROR]: ", errors.New("the history is disabled"))
+		return
+	}
+
+	for _, entry := range app.history.Search(strings.Join(input[1:], " ")) {
+		fmt.Fprintf(app.output, "%5d  %s\n", entry.Number, entry.Line)
+	}
+}
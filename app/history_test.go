@@ -0,0 +1,79 @@
+package app
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/marclop/elasticsearch-cli/history"
+)
+
+func TestApplication_expandHistory(t *testing.T) {
+	h, err := history.Open("", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"GET /", "GET _cat/indices"} {
+		if err := h.Add(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		history *history.History
+		line    string
+		want    string
+		wantErr bool
+	}{
+		{"expandHistoryReturnsTheEntry", h, "!2", "GET _cat/indices", false},
+		{"expandHistoryIgnoresSpaces", h, "  !1 ", "GET /", false},
+		{"expandHistoryFailsWithMissingEntry", h, "!3", "", true},
+		{"expandHistoryFailsWithInvalidNumber", h, "!GET", "", true},
+		{"expandHistoryFailsWhenDisabled", nil, "!1", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &Application{config: &Config{}, history: tt.history}
+			got, err := app.expandHistory(tt.line)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Application.expandHistory() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Application.expandHistory() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplication_doHistoryCommand(t *testing.T) {
+	h, err := history.Open("", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"GET /", "GET _cat/indices", "set output table"} {
+		if err := h.Add(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		input []string
+		want  string
+	}{
+		{"historyPrintsAllEntries", []string{"history"}, "    1  GET /\n    2  GET _cat/indices\n    3  set output table\n"},
+		{"historySearchesEntries", []string{"history", "get"}, "    1  GET /\n    2  GET _cat/indices\n"},
+		{"historySearchesWithSpaces", []string{"history", "output", "table"}, "    3  set output table\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output = new(bytes.Buffer)
+			app := &Application{config: &Config{}, history: h, output: output}
+			app.doHistoryCommand(tt.input)
+			if output.String() != tt.want {
+				t.Errorf("Application.doHistoryCommand() = %q, want %q", output.String(), tt.want)
+			}
+		})
+	}
+}
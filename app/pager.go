@@ -0,0 +1,212 @@
+package app
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+
+	"github.com/chzyer/readline"
+)
+
+const (
+	// pagerPrompt is displayed by the internal pager once a page is full
+	pagerPrompt = "\x1b[7m-- More -- (Enter: next page, q: quit)\x1b[0m"
+	// pagerClearPrompt moves the cursor to the prompt line and clears it
+	pagerClearPrompt = "\x1b[1A\r\x1b[K"
+)
+
+// pagerWriter buffers the output until it exceeds the terminal height, at
+// which point the pager is started and the rest of the output is streamed to
+// it. When the output fits in the terminal, it's written when closed. The
+// pager is either the command in $PAGER or the internal pager.
+type pagerWriter struct {
+	output io.Writer
+	input  io.Reader
+	height int
+
+	buffer  bytes.Buffer
+	lines   int
+	pager   io.Writer
+	closer  func() error
+	discard bool
+}
+
+// terminalHeight returns the number of rows of the file, when it's a terminal.
+// It's a variable so it can be replaced in the tests.
+var terminalHeight = func(f *os.File) (int, bool) {
+	if !readline.IsTerminal(int(f.Fd())) {
+		return 0, false
+	}
+	_, height, err := readline.GetSize(int(f.Fd()))
+	return height, err == nil
+}
+
+// newPagerWriter returns a pagerWriter when the output is a terminal,
+// otherwise the output is returned with a no-op Close method. The internal
+// pager reads the key presses from the input, so it's only used when the
+// input is a terminal too (i.e. it's not a file of requests or a body piped
+// to stdin).
+func newPagerWriter(output io.Writer, input *os.File) io.WriteCloser {
+	f, ok := output.(*os.File)
+	if !ok {
+		return nopWriteCloser{output}
+	}
+
+	height, ok := terminalHeight(f)
+	if !ok || height <= 1 {
+		return nopWriteCloser{output}
+	}
+
+	if _, ok := terminalHeight(input); !ok && os.Getenv("PAGER") == "" {
+		return nopWriteCloser{output}
+	}
+
+	return &pagerWriter{output: output, input: input, height: height}
+}
+
+func (w *pagerWriter) Write(p []byte) (int, error) {
+	if w.discard {
+		return len(p), nil
+	}
+
+	if w.pager != nil {
+		// The pager was closed by the user before reading all of the output
+		if _, err := w.pager.Write(p); err != nil {
+			w.discard = true
+		}
+		return len(p), nil
+	}
+
+	w.buffer.Write(p)
+	w.lines += bytes.Count(p, []byte("\n"))
+	if w.lines < w.height {
+		return len(p), nil
+	}
+
+	if err := w.start(); err != nil {
+		return 0, err
+	}
+
+	if _, err := w.buffer.WriteTo(w.pager); err != nil {
+		w.discard = true
+	}
+	return len(p), nil
+}
+
+// Close writes the buffered output or waits for the pager to exit
+func (w *pagerWriter) Close() error {
+	if w.pager == nil {
+		_, err := w.buffer.WriteTo(w.output)
+		return err
+	}
+
+	if w.closer != nil {
+		return w.closer()
+	}
+	return nil
+}
+
+func (w *pagerWriter) start() error {
+	var command = os.Getenv("PAGER")
+	if command == "" {
+		w.pager = &internalPager{output: w.output, input: w.input, height: w.height}
+		return nil
+	}
+
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdout = w.output
+	cmd.Stderr = os.Stderr
+	// Keep the colours and don't clear the screen when less is used
+	if os.Getenv("LESS") == "" {
+		cmd.Env = append(os.Environ(), "LESS=FRX")
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+
+	// Interrupting the pager shouldn't terminate the application
+	signal.Ignore(os.Interrupt)
+	if err := cmd.Start(); err != nil {
+		signal.Reset(os.Interrupt)
+		return err
+	}
+
+	w.pager = stdin
+	w.closer = func() error {
+		defer signal.Reset(os.Interrupt)
+		stdin.Close()
+		cmd.Wait()
+		return nil
+	}
+	return nil
+}
+
+// internalPager writes the output a page at a time, waiting for the user to
+// press Enter before writing the next page. Entering "q" discards the rest of
+// the output.
+type internalPager struct {
+	output io.Writer
+	input  io.Reader
+	height int
+
+	lines int
+	quit  bool
+}
+
+func (p *internalPager) Write(b []byte) (int, error) {
+	var remaining = b
+	for len(remaining) > 0 && !p.quit {
+		if p.lines >= p.height-1 {
+			p.lines = 0
+			p.quit = p.prompt()
+			continue
+		}
+
+		var line = remaining
+		if i := bytes.IndexByte(remaining, '\n'); i >= 0 {
+			line = remaining[:i+1]
+			p.lines++
+		}
+
+		if _, err := p.output.Write(line); err != nil {
+			return 0, err
+		}
+		remaining = remaining[len(line):]
+	}
+	return len(b), nil
+}
+
+// prompt waits for the user input, returning true when the user has quit
+func (p *internalPager) prompt() bool {
+	io.WriteString(p.output, pagerPrompt)
+
+	// Read a byte at a time so no input is consumed past the line
+	var answer []byte
+	var buf = make([]byte, 1)
+	for {
+		n, err := p.input.Read(buf)
+		if err != nil {
+			return true
+		}
+		if n == 0 {
+			continue
+		}
+		if buf[0] == '\n' {
+			break
+		}
+		answer = append(answer, buf[0])
+	}
+
+	io.WriteString(p.output, pagerClearPrompt)
+	return bytes.EqualFold(bytes.TrimSpace(answer), []byte("q"))
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
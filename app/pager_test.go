@@ -0,0 +1,114 @@
+package app
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestPagerWriter(t *testing.T) {
+	defer os.Setenv("PAGER", os.Getenv("PAGER"))
+	os.Unsetenv("PAGER")
+
+	tests := []struct {
+		name    string
+		height  int
+		content string
+		input   string
+		want    string
+	}{
+		{
+			"PagerWriterWritesOutputThatFitsInTheTerminal",
+			5,
+			"1\n2\n3\n",
+			"",
+			"1\n2\n3\n",
+		},
+		{
+			"PagerWriterPagesOutputThatExceedsTheTerminal",
+			3,
+			"1\n2\n3\n4\n5\n",
+			"\n\n",
+			"1\n2\n" + pagerPrompt + pagerClearPrompt + "3\n4\n" + pagerPrompt + pagerClearPrompt + "5\n",
+		},
+		{
+			"PagerWriterDiscardsTheOutputWhenQuitting",
+			3,
+			"1\n2\n3\n4\n5\n",
+			"q\n",
+			"1\n2\n" + pagerPrompt + pagerClearPrompt,
+		},
+		{
+			"PagerWriterDiscardsTheOutputWhenInputIsClosed",
+			3,
+			"1\n2\n3\n4\n5\n",
+			"",
+			"1\n2\n" + pagerPrompt,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output bytes.Buffer
+			var w = &pagerWriter{output: &output, input: strings.NewReader(tt.input), height: tt.height}
+
+			// Write a line at a time, the streaming formatters write the output in chunks as the body is read
+			for _, line := range strings.SplitAfter(tt.content, "\n") {
+				if _, err := w.Write([]byte(line)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if output.String() != tt.want {
+				t.Errorf("pagerWriter.Write() = %q, want %q", output.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPagerWriter(t *testing.T) {
+	defer os.Setenv("PAGER", os.Getenv("PAGER"))
+	os.Unsetenv("PAGER")
+
+	output, err := ioutil.TempFile("", "output")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(output.Name())
+	defer output.Close()
+
+	input, err := ioutil.TempFile("", "input")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(input.Name())
+	defer input.Close()
+
+	tests := []struct {
+		name      string
+		terminals map[*os.File]bool
+		pager     string
+		wantPager bool
+	}{
+		{"PagerIsUsedWhenOutputAndInputAreTerminals", map[*os.File]bool{output: true, input: true}, "", true},
+		{"PagerIsNotUsedWhenOutputIsNotATerminal", map[*os.File]bool{input: true}, "", false},
+		{"PagerIsNotUsedWhenInputIsNotATerminal", map[*os.File]bool{output: true}, "", false},
+		{"PagerCommandIsUsedWhenInputIsNotATerminal", map[*os.File]bool{output: true}, "less", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func(f func(*os.File) (int, bool)) { terminalHeight = f }(terminalHeight)
+			terminalHeight = func(f *os.File) (int, bool) { return 24, tt.terminals[f] }
+			os.Setenv("PAGER", tt.pager)
+
+			_, gotPager := newPagerWriter(output, input).(*pagerWriter)
+			if gotPager != tt.wantPager {
+				t.Errorf("newPagerWriter() pager = %v, want %v", gotPager, tt.wantPager)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/marclop/elasticsearch-cli/credentials"
+)
+
+// maxPasswordPrompts is the number of times the password is prompted for
+// when a request fails with a 401
+const maxPasswordPrompts = 3
+
+// password returns the basic authentication password, which is resolved from
+// the first of pass, pass-command, pass-file or the credential store that is
+// set, and prompted for otherwise. The settings overridden by a higher
+// precedence source are expected to be cleared already. It's only resolved
+// when the password is used, that is when there's a user and no API key or
+// token.
+func (c *Config) password() (string, error) {
+	if !c.basicAuth() || c.Pass != "" {
+		return c.Pass, nil
+	}
+
+	switch {
+	case c.PassCommand != "":
+		return runPassCommand(c.PassCommand)
+	case c.PassFile != "":
+		return readPassFile(c.PassFile)
+	case c.CredentialStore != nil:
+		pass, err := c.CredentialStore.Get(c.Cluster)
+		if err != credentials.ErrNotFound {
+			return pass, err
+		}
+	}
+
+	if c.PasswordPrompt != nil {
+		return c.PasswordPrompt(passwordPrompt(c.User))
+	}
+	return "", nil
+}
+
+// basicAuth returns true when the user and password are used to authenticate
+func (c *Config) basicAuth() bool {
+	return c.User != "" && c.APIKey == "" && c.Token == "" && c.CloudAuth == ""
+}
+
+func passwordPrompt(user string) string {
+	return fmt.Sprintf("Password for %s: ", user)
+}
+
+// runPassCommand runs the command with sh, returning its output without the
+// trailing newline. Stdin and stderr are kept so the command can prompt
+// (i.e. for a GPG passphrase).
+func runPassCommand(command string) (string, error) {
+	var stdout bytes.Buffer
+	var cmd = exec.Command("sh", "-c", command)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("pass command \"%s\" failed: %s", command, err)
+	}
+
+	var pass = trimNewline(stdout.String())
+	if pass == "" {
+		return "", fmt.Errorf("pass command \"%s\" returned an empty password", command)
+	}
+	return pass, nil
+}
+
+// readPassFile reads the password from the file, which must only be
+// accessible by its owner
+func readPassFile(path string) (string, error) {
+	if err := credentials.CheckPermissions(path); err != nil {
+		return "", err
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return trimNewline(string(content)), nil
+}
+
+// trimNewline removes the trailing newline, keeping any other whitespace
+func trimNewline(value string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(value, "\n"), "\r")
+}
+
+// repromptPassword prompts for the password when the response is a 401 and
+// basic authentication is used, returning true when the request needs to be
+// sent again with the new password.
+func (app *Application) repromptPassword(res *http.Response, body io.Reader) bool {
+	if res.StatusCode != http.StatusUnauthorized || !app.basicAuth() {
+		return false
+	}
+
+	if !app.canPromptPassword() || !rewind(body) {
+		return false
+	}
+
+	io.Copy(ioutil.Discard, res.Body)
+	res.Body.Close()
+
+	log.Print("[ERROR]: ", fmt.Sprintf("authentication failed for user \"%s\"", app.client.Config.User))
+	if err := app.setPassword(); err != nil {
+		log.Print("[ERROR]: ", err)
+		return false
+	}
+	return true
+}
+
+// basicAuth returns true when the client authenticates with the user and
+// password, taking into account the credentials changed in interactive mode
+func (app *Application) basicAuth() bool {
+	var config = Config{
+		User:      app.client.Config.User,
+		APIKey:    app.client.Config.APIKey,
+		Token:     app.client.Config.Token,
+		CloudAuth: app.config.CloudAuth,
+	}
+	return config.basicAuth()
+}
+
+// setPassword prompts for the password of the user without echoing it
+func (app *Application) setPassword() error {
+	if !app.canPromptPassword() {
+		return errors.New("can't prompt for the password, stdin is not a terminal")
+	}
+
+	var prompt = passwordPrompt(app.client.Config.User)
+	if app.repl != nil && app.repl.Operation != nil {
+		pass, err := app.repl.ReadPassword(prompt)
+		if err != nil {
+			return err
+		}
+		app.client.Config.Pass = string(pass)
+		return nil
+	}
+
+	pass, err := app.config.PasswordPrompt(prompt)
+	if err != nil {
+		return err
+	}
+	app.client.Config.Pass = pass
+	return nil
+}
+
+func (app *Application) canPromptPassword() bool {
+	return (app.repl != nil && app.repl.Operation != nil) || app.config.PasswordPrompt != nil
+}
+
+// rewind seeks the request body back to its start, returning false when it
+// can't be read again (i.e. it's streamed from stdin)
+func rewind(body io.Reader) bool {
+	if body == nil {
+		return true
+	}
+
+	seeker, ok := body.(io.Seeker)
+	if !ok {
+		return false
+	}
+	_, err := seeker.Seek(0, io.SeekStart)
+	return err == nil
+}
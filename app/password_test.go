@@ -0,0 +1,275 @@
+package app
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/marclop/elasticsearch-cli/cli"
+	"github.com/marclop/elasticsearch-cli/client"
+	"github.com/marclop/elasticsearch-cli/credentials"
+)
+
+func TestConfig_password(t *testing.T) {
+	dir, err := ioutil.TempDir("", "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var ownerFile, openFile = filepath.Join(dir, "owner"), filepath.Join(dir, "open")
+	if err := ioutil.WriteFile(ownerFile, []byte("filepass\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(openFile, []byte("filepass\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// WriteFile is subject to the umask
+	if err := os.Chmod(openFile, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var store = credentials.NewStore(filepath.Join(dir, ".elasticsearch-cli"))
+	if err := store.Set("prod", "storepass"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		config  *Config
+		want    string
+		wantErr bool
+	}{
+		{
+			"passwordPrefersPass",
+			&Config{User: "marc", Pass: "mypass", PassCommand: "exit 1"},
+			"mypass",
+			false,
+		},
+		{
+			"passwordIsNotResolvedWithoutUser",
+			&Config{PassCommand: "exit 1"},
+			"",
+			false,
+		},
+		{
+			"passwordIsNotResolvedWithAPIKey",
+			&Config{User: "marc", APIKey: "key", PassCommand: "exit 1"},
+			"",
+			false,
+		},
+		{
+			"passwordRunsPassCommand",
+			&Config{User: "marc", PassCommand: "printf 'my pass\\n'", PassFile: ownerFile},
+			"my pass",
+			false,
+		},
+		{
+			"passwordFailsWithFailingPassCommand",
+			&Config{User: "marc", PassCommand: "exit 1"},
+			"",
+			true,
+		},
+		{
+			"passwordFailsWithEmptyPassCommand",
+			&Config{User: "marc", PassCommand: "true"},
+			"",
+			true,
+		},
+		{
+			"passwordReadsPassFile",
+			&Config{User: "marc", PassFile: ownerFile, CredentialStore: store, Cluster: "prod"},
+			"filepass",
+			false,
+		},
+		{
+			"passwordFailsWithOpenPassFile",
+			&Config{User: "marc", PassFile: openFile},
+			"",
+			true,
+		},
+		{
+			"passwordReadsCredentialStore",
+			&Config{User: "marc", CredentialStore: store, Cluster: "prod"},
+			"storepass",
+			false,
+		},
+		{
+			"passwordIsEmptyWhenNotStored",
+			&Config{User: "marc", CredentialStore: store, Cluster: "staging"},
+			"",
+			false,
+		},
+		{
+			"passwordIsPromptedForWhenNotStored",
+			&Config{
+				User:            "marc",
+				CredentialStore: store,
+				Cluster:         "staging",
+				PasswordPrompt:  func(string) (string, error) { return "prompted", nil },
+			},
+			"prompted",
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.config.password()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Config.password() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Config.password() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// basicAuthServer responds with a 401 unless the request is authenticated
+// with the password, recording the bodies it receives
+type basicAuthServer struct {
+	pass   string
+	bodies []string
+}
+
+func (s *basicAuthServer) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body string
+	if req.Body != nil {
+		content, _ := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		body = string(content)
+	}
+	s.bodies = append(s.bodies, body)
+
+	var status = http.StatusOK
+	if _, pass, ok := req.BasicAuth(); !ok || pass != s.pass {
+		status = http.StatusUnauthorized
+	}
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Body:       ioutil.NopCloser(strings.NewReader("{}")),
+		Request:    req,
+	}, nil
+}
+
+func TestApplication_repromptPassword(t *testing.T) {
+	tests := []struct {
+		name       string
+		prompts    []string
+		cloudAuth  string
+		body       func() io.Reader
+		wantStatus int
+		wantBodies []string
+	}{
+		{
+			"password is prompted for on a 401",
+			[]string{"secret"},
+			"",
+			func() io.Reader { return strings.NewReader(`{"query":{}}`) },
+			200,
+			[]string{`{"query":{}}`, `{"query":{}}`},
+		},
+		{
+			"password is prompted for up to 3 times",
+			[]string{"wrong", "wrong", "wrong", "secret"},
+			"",
+			func() io.Reader { return nil },
+			401,
+			[]string{"", "", "", ""},
+		},
+		{
+			"password is not prompted for without a terminal",
+			nil,
+			"",
+			func() io.Reader { return nil },
+			401,
+			[]string{""},
+		},
+		{
+			"password is not prompted for with Cloud credentials",
+			[]string{"secret"},
+			"marc:wrong",
+			func() io.Reader { return nil },
+			401,
+			[]string{""},
+		},
+		{
+			"password is not prompted for with a streamed body",
+			[]string{"secret"},
+			"",
+			func() io.Reader { return ioutil.NopCloser(strings.NewReader(`{"query":{}}`)) },
+			401,
+			[]string{`{"query":{}}`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var server = &basicAuthServer{pass: "secret"}
+			config, err := client.NewClientConfig("http://localhost", 9200, "marc", "wrong", 10, client.TLSConfig{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var prompts = tt.prompts
+			var appConfig = &Config{NoPager: true, CloudAuth: tt.cloudAuth}
+			if prompts != nil {
+				appConfig.PasswordPrompt = func(prompt string) (string, error) {
+					if prompt != "Password for marc: " {
+						t.Errorf("PasswordPrompt() prompt = %v", prompt)
+					}
+					var pass = prompts[0]
+					prompts = prompts[1:]
+					return pass, nil
+				}
+			}
+
+			app := &Application{
+				config:     appConfig,
+				client:     client.NewHTTP(config, &http.Client{Transport: server}),
+				formatFunc: cli.Format,
+				output:     &bytes.Buffer{},
+			}
+			status, err := app.handleRequest(&cli.InputParser{Method: "GET", Path: "/", Body: tt.body()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status != tt.wantStatus {
+				t.Errorf("Application.handleRequest() = %v, want %v", status, tt.wantStatus)
+			}
+			if !reflect.DeepEqual(server.bodies, tt.wantBodies) {
+				t.Errorf("Application.handleRequest() bodies = %v, want %v", server.bodies, tt.wantBodies)
+			}
+		})
+	}
+}
+
+func TestApplication_setPassword(t *testing.T) {
+	config, err := client.NewClientConfig("http://localhost", 9200, "marc", "", 10, client.TLSConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := &Application{
+		config: &Config{},
+		client: client.NewHTTP(config, client.NewMock()),
+		output: &bytes.Buffer{},
+	}
+	app.doSetCommands([]string{"set", "pass"})
+	if app.client.Config.Pass != "" {
+		t.Errorf("app.client.Config.Pass = %v, want it unset without a terminal", app.client.Config.Pass)
+	}
+
+	app.config.PasswordPrompt = func(string) (string, error) { return "prompted", nil }
+	app.doSetCommands([]string{"set", "pass"})
+	if app.client.Config.Pass != "prompted" {
+		t.Errorf("app.client.Config.Pass = %v, want %v", app.client.Config.Pass, "prompted")
+	}
+}
@@ -0,0 +1,72 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/marclop/elasticsearch-cli/cli"
+)
+
+// RunOptions controls how the requests are executed by Run
+type RunOptions struct {
+	// StopOnError stops the execution on the first failed request, either
+	// because its response has an HTTP error status (>= 400) or because it
+	// cannot be performed at all (i.e. connection refused). By default the
+	// failures are counted and the remaining requests are executed.
+	StopOnError bool
+}
+
+// RunSummary contains the results of a Run
+type RunSummary struct {
+	Succeeded int
+	Failed    int
+	Skipped   int
+}
+
+// String returns the human readable version of the summary
+func (s RunSummary) String() string {
+	return fmt.Sprintf("%d succeeded, %d failed, %d skipped", s.Succeeded, s.Failed, s.Skipped)
+}
+
+// Run executes in order all of the requests contained in the Kibana console
+// formatted reader, formatting each of the responses. When any of the requests
+// fails, an error is returned once the summary has been printed.
+func (app *Application) Run(r io.Reader, opts RunOptions) error {
+	requests, err := cli.ParseConsole(r)
+	if err != nil {
+		return err
+	}
+
+	summary := app.runRequests(requests, opts)
+	log.Print("Requests: ", summary)
+
+	if summary.Failed > 0 {
+		return fmt.Errorf("%d out of %d requests failed", summary.Failed, len(requests))
+	}
+	return nil
+}
+
+func (app *Application) runRequests(requests []*cli.InputParser, opts RunOptions) RunSummary {
+	var summary RunSummary
+	for i, input := range requests {
+		status, err := app.handleRequest(input)
+		if err != nil {
+			log.Printf("[ERROR]: %s %s: %s", input.Method, input.URL(), err)
+		}
+
+		if err == nil && status < http.StatusBadRequest {
+			summary.Succeeded++
+			continue
+		}
+
+		summary.Failed++
+		if opts.StopOnError {
+			summary.Skipped = len(requests) - i - 1
+			break
+		}
+	}
+
+	return summary
+}
@@ -0,0 +1,142 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/marclop/elasticsearch-cli/cli"
+	"github.com/marclop/elasticsearch-cli/client"
+)
+
+func newRunResponse(status int) client.MockResponse {
+	return client.MockResponse{Response: http.Response{
+		StatusCode: status,
+		Request:    &http.Request{Method: "GET", URL: new(url.URL)},
+		Body:       client.NewStringBody(`{"acknowledged": true}`),
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+	}}
+}
+
+func TestApplication_runRequests(t *testing.T) {
+	var requests = `PUT myindex
+{"settings": {"number_of_shards": 1}}
+
+GET myindex/_search
+{"query": {"match_all": {}}}
+
+DELETE myindex
+`
+	type args struct {
+		responses []client.MockResponse
+		opts      RunOptions
+	}
+	tests := []struct {
+		name string
+		args args
+		want RunSummary
+	}{
+		{
+			"RunSucceedsWhenAllRequestsSucceed",
+			args{
+				[]client.MockResponse{newRunResponse(200), newRunResponse(200), newRunResponse(200)},
+				RunOptions{},
+			},
+			RunSummary{Succeeded: 3},
+		},
+		{
+			"RunKeepsGoingWhenResponseHasErrorStatus",
+			args{
+				[]client.MockResponse{newRunResponse(200), newRunResponse(404), newRunResponse(200)},
+				RunOptions{},
+			},
+			RunSummary{Succeeded: 2, Failed: 1},
+		},
+		{
+			"RunStopsWhenResponseHasErrorStatusAndStopOnError",
+			args{
+				[]client.MockResponse{newRunResponse(400), newRunResponse(200), newRunResponse(200)},
+				RunOptions{StopOnError: true},
+			},
+			RunSummary{Failed: 1, Skipped: 2},
+		},
+		{
+			"RunKeepsGoingWhenRequestCannotBePerformed",
+			args{
+				[]client.MockResponse{newRunResponse(200), {Error: errors.New("connection refused")}, newRunResponse(200)},
+				RunOptions{},
+			},
+			RunSummary{Succeeded: 2, Failed: 1},
+		},
+		{
+			"RunStopsWhenRequestCannotBePerformedAndStopOnError",
+			args{
+				[]client.MockResponse{newRunResponse(200), {Error: errors.New("connection refused")}, newRunResponse(200)},
+				RunOptions{StopOnError: true},
+			},
+			RunSummary{Succeeded: 1, Failed: 1, Skipped: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := cli.ParseConsole(strings.NewReader(requests))
+			if err != nil {
+				t.Fatal(err)
+			}
+			app := &Application{
+				config:     &Config{},
+				client:     client.NewHTTP(defaultConfig, client.NewMock(tt.args.responses...)),
+				formatFunc: cli.Format,
+				output:     &bytes.Buffer{},
+			}
+			if got := app.runRequests(parsed, tt.args.opts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Application.runRequests() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplication_Run(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		responses []client.MockResponse
+		wantErr   bool
+	}{
+		{
+			"RunSucceeds",
+			"GET /\nGET _cat/indices\n",
+			[]client.MockResponse{newRunResponse(200), newRunResponse(200)},
+			false,
+		},
+		{
+			"RunFailsWhenAnyRequestFails",
+			"GET /\nGET _cat/indices\n",
+			[]client.MockResponse{newRunResponse(200), newRunResponse(500)},
+			true,
+		},
+		{
+			"RunFailsWhenInputIsInvalid",
+			"{}",
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &Application{
+				config:     &Config{},
+				client:     client.NewHTTP(defaultConfig, client.NewMock(tt.responses...)),
+				formatFunc: cli.Format,
+				output:     &bytes.Buffer{},
+			}
+			if err := app.Run(strings.NewReader(tt.input), RunOptions{}); (err != nil) != tt.wantErr {
+				t.Errorf("Application.Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
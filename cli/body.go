@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+const stdinBody = "-"
+
+// NewBodyReader returns the request body specified by value, following the
+// curl conventions: "@<file>" streams the body from the file, "@-" from stdin
+// and any other value is used as the body itself. Unless binary is set, the
+// newlines and carriage returns are stripped from file and stdin contents,
+// which is harmless for JSON bodies. NDJSON bodies (i.e. _bulk) need binary.
+func NewBodyReader(value string, binary bool) (io.ReadCloser, error) {
+	if !strings.HasPrefix(value, "@") {
+		return ioutil.NopCloser(strings.NewReader(value)), nil
+	}
+
+	var source io.ReadCloser = ioutil.NopCloser(os.Stdin)
+	if path := strings.TrimPrefix(value, "@"); path != stdinBody {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		source = f
+	}
+
+	if binary {
+		return source, nil
+	}
+
+	return &newlineStripper{reader: bufio.NewReader(source), closer: source}, nil
+}
+
+// SplitRedirect detects a "METHOD url < file" (or "<file") redirect in the
+// REPL input, returning the request arguments and the redirected file. When
+// there's no redirect, the input is returned unmodified and the file is empty.
+func SplitRedirect(input []string) ([]string, string) {
+	switch {
+	case len(input) == 4 && input[2] == "<":
+		return input[:2], input[3]
+	case len(input) == 3 && len(input[2]) > 1 && strings.HasPrefix(input[2], "<"):
+		return input[:2], strings.TrimPrefix(input[2], "<")
+	}
+	return input, ""
+}
+
+// newlineStripper removes all of the newlines and carriage returns from the
+// underlying reader while streaming it.
+type newlineStripper struct {
+	reader *bufio.Reader
+	closer io.Closer
+}
+
+func (s *newlineStripper) Read(p []byte) (int, error) {
+	var n int
+	for n < len(p) {
+		b, err := s.reader.ReadByte()
+		if err != nil {
+			if n > 0 && err == io.EOF {
+				return n, nil
+			}
+			return n, err
+		}
+		if b == '\n' || b == '\r' {
+			continue
+		}
+		p[n] = b
+		n++
+		if s.reader.Buffered() == 0 {
+			break
+		}
+	}
+	return n, nil
+}
+
+func (s *newlineStripper) Close() error {
+	return s.closer.Close()
+}
@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNewBodyReader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "elasticsearch-cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var bulk = filepath.Join(dir, "bulk.json")
+	var content = "{\"index\": {\"_index\": \"test\"}}\r\n{\"message\": \"hello  world\"}\n"
+	if err := ioutil.WriteFile(bulk, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	type args struct {
+		value  string
+		binary bool
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			"NewBodyReaderSucceedsWithLiteralBody",
+			args{`{"query": {"match_all": {}}}`, false},
+			`{"query": {"match_all": {}}}`,
+			false,
+		},
+		{
+			"NewBodyReaderStripsNewlinesFromFiles",
+			args{"@" + bulk, false},
+			`{"index": {"_index": "test"}}{"message": "hello  world"}`,
+			false,
+		},
+		{
+			"NewBodyReaderKeepsFileContentsWhenBinary",
+			args{"@" + bulk, true},
+			content,
+			false,
+		},
+		{
+			"NewBodyReaderFailsWhenFileDoesNotExist",
+			args{"@" + filepath.Join(dir, "missing.json"), false},
+			"",
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewBodyReader(tt.args.value, tt.args.binary)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewBodyReader() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			defer got.Close()
+
+			body, err := ioutil.ReadAll(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(body) != tt.want {
+				t.Errorf("NewBodyReader() = %v, want %v", string(body), tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitRedirect(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []string
+		wantArgs []string
+		wantFile string
+	}{
+		{
+			"SplitRedirectWithoutRedirect",
+			[]string{"GET", "/_search"},
+			[]string{"GET", "/_search"},
+			"",
+		},
+		{
+			"SplitRedirectWithSeparatedRedirect",
+			[]string{"GET", "/_search", "<", "query.json"},
+			[]string{"GET", "/_search"},
+			"query.json",
+		},
+		{
+			"SplitRedirectWithJoinedRedirect",
+			[]string{"POST", "/_bulk", "<bulk.json"},
+			[]string{"POST", "/_bulk"},
+			"bulk.json",
+		},
+		{
+			"SplitRedirectIgnoresInlineBodies",
+			[]string{"GET", "/_search", `{"query":`, "<", "1}"},
+			[]string{"GET", "/_search", `{"query":`, "<", "1}"},
+			"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotArgs, gotFile := SplitRedirect(tt.input)
+			if !reflect.DeepEqual(gotArgs, tt.wantArgs) {
+				t.Errorf("SplitRedirect() args = %v, want %v", gotArgs, tt.wantArgs)
+			}
+			if gotFile != tt.wantFile {
+				t.Errorf("SplitRedirect() file = %v, want %v", gotFile, tt.wantFile)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const colorReset = "\x1b[0m"
+
+// colorCodes maps the color names to their ANSI SGR codes
+var colorCodes = map[string]string{
+	"black":   "30",
+	"red":     "31",
+	"green":   "32",
+	"yellow":  "33",
+	"blue":    "34",
+	"magenta": "35",
+	"cyan":    "36",
+	"white":   "37",
+	"gray":    "90",
+}
+
+// Theme contains the ANSI SGR codes used to colourise each of the JSON
+// elements, an empty code leaves the element uncoloured
+type Theme struct {
+	Key     string
+	String  string
+	Number  string
+	Boolean string
+	Null    string
+}
+
+// DefaultTheme is the Theme used when none is configured
+var DefaultTheme = Theme{
+	Key:     "34",
+	String:  "32",
+	Number:  "36",
+	Boolean: "33",
+	Null:    "90",
+}
+
+// NewTheme returns the DefaultTheme overriding the elements specified in
+// colors, which maps the element names (key, string, number, boolean and
+// null) to either a color name (i.e. "blue" or "bold blue") or an SGR code
+// (i.e. "1;34").
+func NewTheme(colors map[string]string) (Theme, error) {
+	var theme = DefaultTheme
+	for element, color := range colors {
+		code, err := colorCode(color)
+		if err != nil {
+			return theme, fmt.Errorf("invalid color for theme element \"%s\": %s", element, err)
+		}
+
+		switch strings.ToLower(element) {
+		case "key":
+			theme.Key = code
+		case "string":
+			theme.String = code
+		case "number":
+			theme.Number = code
+		case "boolean":
+			theme.Boolean = code
+		case "null":
+			theme.Null = code
+		default:
+			return theme, fmt.Errorf("theme element \"%s\" is not supported, must be one of key, string, number, boolean, null", element)
+		}
+	}
+	return theme, nil
+}
+
+func colorCode(color string) (string, error) {
+	var codes []string
+	for _, part := range strings.Fields(strings.ToLower(color)) {
+		if part == "bold" {
+			codes = append(codes, "1")
+			continue
+		}
+		if code, ok := colorCodes[part]; ok {
+			codes = append(codes, code)
+			continue
+		}
+		for _, n := range strings.Split(part, ";") {
+			if _, err := strconv.Atoi(n); err != nil {
+				return "", fmt.Errorf("unknown color \"%s\"", color)
+			}
+		}
+		codes = append(codes, part)
+	}
+	return strings.Join(codes, ";"), nil
+}
+
+// colorizeJSON returns the already formatted JSON content with its keys,
+// strings, numbers, booleans and nulls coloured as specified by the theme.
+// Whitespace and punctuation are kept as they are.
+func colorizeJSON(content []byte, theme Theme) []byte {
+	var out bytes.Buffer
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case c == '"':
+			end := stringEnd(content, i)
+			code := theme.String
+			if isKey(content, end) {
+				code = theme.Key
+			}
+			writeColored(&out, content[i:end], code)
+			i = end
+		case c == '-' || (c >= '0' && c <= '9'):
+			end := i + 1
+			for end < len(content) && strings.IndexByte("0123456789.eE+-", content[end]) >= 0 {
+				end++
+			}
+			writeColored(&out, content[i:end], theme.Number)
+			i = end
+		case bytes.HasPrefix(content[i:], []byte("true")):
+			writeColored(&out, content[i:i+4], theme.Boolean)
+			i += 4
+		case bytes.HasPrefix(content[i:], []byte("false")):
+			writeColored(&out, content[i:i+5], theme.Boolean)
+			i += 5
+		case bytes.HasPrefix(content[i:], []byte("null")):
+			writeColored(&out, content[i:i+4], theme.Null)
+			i += 4
+		default:
+			out.WriteByte(c)
+			i++
+		}
+	}
+	return out.Bytes()
+}
+
+// stringEnd returns the position right after the string which starts at i
+func stringEnd(content []byte, i int) int {
+	for j := i + 1; j < len(content); j++ {
+		switch content[j] {
+		case '\\':
+			j++
+		case '"':
+			return j + 1
+		}
+	}
+	return len(content)
+}
+
+// isKey returns true when the next non whitespace character is a colon
+func isKey(content []byte, i int) bool {
+	for ; i < len(content); i++ {
+		switch content[i] {
+		case ' ', '\t', '\n', '\r':
+			continue
+		case ':':
+			return true
+		}
+		return false
+	}
+	return false
+}
+
+func writeColored(out *bytes.Buffer, token []byte, code string) {
+	if code == "" {
+		out.Write(token)
+		return
+	}
+	out.WriteString("\x1b[")
+	out.WriteString(code)
+	out.WriteString("m")
+	out.Write(token)
+	out.WriteString(colorReset)
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestColorizeJSON(t *testing.T) {
+	var theme = Theme{Key: "k", String: "s", Number: "n", Boolean: "b", Null: "x"}
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			"ColorizeJSONSucceedsWithAllTypes",
+			`{"a": "b", "c": -1.5e3, "d": [true, false, null]}`,
+			"{\x1b[km\"a\"\x1b[0m: \x1b[sm\"b\"\x1b[0m, \x1b[km\"c\"\x1b[0m: \x1b[nm-1.5e3\x1b[0m, " +
+				"\x1b[km\"d\"\x1b[0m: [\x1b[bmtrue\x1b[0m, \x1b[bmfalse\x1b[0m, \x1b[xmnull\x1b[0m]}",
+		},
+		{
+			"ColorizeJSONSucceedsWithEscapedQuotes",
+			`{"a\"": "b\":c"}`,
+			"{\x1b[km\"a\\\"\"\x1b[0m: \x1b[sm\"b\\\":c\"\x1b[0m}",
+		},
+		{
+			"ColorizeJSONSucceedsWithIndentedKeys",
+			"{\n  \"a\"  :\n  1\n}",
+			"{\n  \x1b[km\"a\"\x1b[0m  :\n  \x1b[nm1\x1b[0m\n}",
+		},
+		{
+			"ColorizeJSONLeavesUncolouredElements",
+			`["a", 1]`,
+			"[\x1b[sm\"a\"\x1b[0m, \x1b[nm1\x1b[0m]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(colorizeJSON([]byte(tt.content), theme)); got != tt.want {
+				t.Errorf("colorizeJSON() = %q, want = %q", got, tt.want)
+			}
+		})
+	}
+
+	if got := string(colorizeJSON([]byte(`{"a": 1}`), Theme{})); got != `{"a": 1}` {
+		t.Errorf("colorizeJSON() with an empty theme = %q, want = %q", got, `{"a": 1}`)
+	}
+}
+
+func TestNewTheme(t *testing.T) {
+	tests := []struct {
+		name    string
+		colors  map[string]string
+		want    Theme
+		wantErr bool
+	}{
+		{
+			"NewThemeReturnsDefaultTheme",
+			nil,
+			DefaultTheme,
+			false,
+		},
+		{
+			"NewThemeOverridesElements",
+			map[string]string{"key": "bold magenta", "Null": "1;31", "string": ""},
+			Theme{Key: "1;35", String: "", Number: DefaultTheme.Number, Boolean: DefaultTheme.Boolean, Null: "1;31"},
+			false,
+		},
+		{
+			"NewThemeFailsWithUnknownColor",
+			map[string]string{"key": "purple"},
+			DefaultTheme,
+			true,
+		},
+		{
+			"NewThemeFailsWithUnknownElement",
+			map[string]string{"punctuation": "red"},
+			DefaultTheme,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewTheme(tt.colors)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewTheme() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewTheme() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -86,11 +86,28 @@ var putAPI = []readline.PrefixCompleterInterface{
 var setCompleter = readline.PcItem("set",
 	readline.PcItem("user"),
 	readline.PcItem("pass"),
+	readline.PcItem("apikey"),
+	readline.PcItem("token"),
 	readline.PcItem("host"),
 	readline.PcItem("port"),
 	readline.PcItem("verbose"),
+	readline.PcItem("output", outputCompletions()...),
+	readline.PcItem("pager", readline.PcItem("on"), readline.PcItem("off")),
+	readline.PcItem("header"),
 )
 
+var unsetCompleter = readline.PcItem("unset",
+	readline.PcItem("header"),
+)
+
+func outputCompletions() []readline.PrefixCompleterInterface {
+	var completions []readline.PrefixCompleterInterface
+	for _, output := range OutputFormats {
+		completions = append(completions, readline.PcItem(output))
+	}
+	return completions
+}
+
 // Completer has the initial list for the interactive completions
 var Completer = readline.NewPrefixCompleter(
 	readline.PcItem("GET", getAPI...),
@@ -99,6 +116,9 @@ var Completer = readline.NewPrefixCompleter(
 	readline.PcItem("HEAD"),
 	readline.PcItem("DELETE"),
 	setCompleter,
+	unsetCompleter,
+	readline.PcItem("use"),
+	readline.PcItem("history"),
 )
 
 // AssembleIndexCompleter creates the autocompletion index for REPL
@@ -149,5 +169,8 @@ func AssembleIndexCompleter(indices []string) readline.PrefixCompleterInterface
 		readline.PcItem("HEAD", indexCompleterList...),
 		readline.PcItem("DELETE", indexCompleterList...),
 		setCompleter,
+		unsetCompleter,
+		readline.PcItem("use"),
+		readline.PcItem("history"),
 	)
 }
@@ -0,0 +1,238 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/marclop/elasticsearch-cli/utils"
+)
+
+// ConsoleParser parses input written in the Kibana Dev Tools console syntax,
+// where every request starts with a "METHOD url" line followed by an optional
+// body, which can span multiple lines. Bodies are either a single JSON document
+// or a set of newline delimited JSON documents (NDJSON), like the ones taken by
+// the _bulk and _msearch APIs. Lines starting with "#" or "//" are treated as
+// comments and ignored.
+type ConsoleParser struct {
+	requests []*InputParser
+	current  *consoleRequest
+	line     int
+}
+
+// consoleRequest holds a request which body is still being read
+type consoleRequest struct {
+	input *InputParser
+	line  int
+	body  bytes.Buffer
+	depth int
+
+	inString bool
+	escaped  bool
+}
+
+// NewConsoleParser creates an empty ConsoleParser
+func NewConsoleParser() *ConsoleParser {
+	return &ConsoleParser{}
+}
+
+// ParseConsole splits the contents of the reader into the requests it contains
+func ParseConsole(r io.Reader) ([]*InputParser, error) {
+	var parser = NewConsoleParser()
+	var reader = bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			if perr := parser.ParseLine(line); perr != nil {
+				return nil, perr
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return parser.Requests()
+}
+
+// ParseLine parses a single line of console input
+func (p *ConsoleParser) ParseLine(line string) error {
+	p.line++
+	line = strings.TrimRight(line, "\r\n")
+	trimmed := strings.TrimSpace(line)
+
+	// Comments are only honoured outside of JSON strings
+	if p.current == nil || !p.current.inString {
+		if strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "//") {
+			return nil
+		}
+	}
+
+	if !p.Pending() {
+		if trimmed == "" {
+			return nil
+		}
+
+		if IsRequestLine(trimmed) {
+			if err := p.flush(); err != nil {
+				return err
+			}
+			return p.startRequest(trimmed, strings.Fields(trimmed))
+		}
+
+		if p.current == nil {
+			return fmt.Errorf("line %d: expected a request line (METHOD url), got \"%s\"", p.line, trimmed)
+		}
+	}
+
+	p.current.write(line)
+	return nil
+}
+
+// Pending returns true when the current request body is still incomplete
+// and more lines are needed to finish it
+func (p *ConsoleParser) Pending() bool {
+	return p.current != nil && (p.current.depth > 0 || p.current.inString)
+}
+
+// Open returns true when a request has been started and not returned by
+// Requests yet, since more body lines might follow
+func (p *ConsoleParser) Open() bool {
+	return p.current != nil
+}
+
+// Requests returns all of the requests parsed so far and resets the parser
+func (p *ConsoleParser) Requests() ([]*InputParser, error) {
+	if p.Pending() {
+		err := fmt.Errorf("line %d: unterminated request body", p.current.line)
+		p.Reset()
+		return nil, err
+	}
+
+	if err := p.flush(); err != nil {
+		p.Reset()
+		return nil, err
+	}
+
+	var requests = p.requests
+	p.Reset()
+	return requests, nil
+}
+
+// Reset discards any parsed or partially parsed requests
+func (p *ConsoleParser) Reset() {
+	p.requests = nil
+	p.current = nil
+	p.line = 0
+}
+
+// IsRequestLine returns true when the line starts with an HTTP method, that is
+// when it starts a new request
+func IsRequestLine(line string) bool {
+	fields := strings.Fields(line)
+	return len(fields) > 0 && utils.StringInSlice(strings.ToUpper(fields[0]), SupportedMethods)
+}
+
+func (p *ConsoleParser) startRequest(line string, fields []string) error {
+	var args = fields
+	if len(args) > 2 {
+		args = args[:2]
+	}
+
+	input, err := NewInputParser(args)
+	if err != nil {
+		return fmt.Errorf("line %d: %s", p.line, err)
+	}
+
+	p.current = &consoleRequest{input: input, line: p.line}
+
+	// Keep supporting bodies that are specified in the request line itself
+	if len(fields) > 2 {
+		rest := strings.TrimSpace(line[len(fields[0]):])
+		p.current.write(strings.TrimSpace(rest[len(fields[1]):]))
+	}
+	return nil
+}
+
+func (p *ConsoleParser) flush() error {
+	if p.current == nil {
+		return nil
+	}
+
+	body, err := p.current.assembleBody()
+	if err != nil {
+		return fmt.Errorf("line %d: invalid request body: %s", p.current.line, err)
+	}
+
+	if body != "" {
+		p.current.input.Body = strings.NewReader(body)
+	}
+	p.requests = append(p.requests, p.current.input)
+	p.current = nil
+	return nil
+}
+
+// write appends the line to the request body, keeping track of the JSON
+// nesting so it's known when a multi-line body is complete
+func (r *consoleRequest) write(line string) {
+	for _, c := range line {
+		switch {
+		case r.escaped:
+			r.escaped = false
+		case r.inString && c == '\\':
+			r.escaped = true
+		case c == '"':
+			r.inString = !r.inString
+		case r.inString:
+		case c == '{' || c == '[':
+			r.depth++
+		case c == '}' || c == ']':
+			r.depth--
+		}
+	}
+	r.body.WriteString(line)
+	r.body.WriteString("\n")
+}
+
+// assembleBody validates the body, returning it as is when it consists of a
+// single JSON document. Multiple documents are compacted one per line, since
+// that's the format that Elasticsearch expects for NDJSON bodies.
+func (r *consoleRequest) assembleBody() (string, error) {
+	var content = strings.TrimSpace(r.body.String())
+	if content == "" {
+		return "", nil
+	}
+
+	var documents []json.RawMessage
+	var decoder = json.NewDecoder(strings.NewReader(content))
+	for {
+		var document json.RawMessage
+		err := decoder.Decode(&document)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		documents = append(documents, document)
+	}
+
+	if len(documents) == 1 {
+		return content, nil
+	}
+
+	var ndjson bytes.Buffer
+	for _, document := range documents {
+		if err := json.Compact(&ndjson, document); err != nil {
+			return "", err
+		}
+		ndjson.WriteString("\n")
+	}
+	return ndjson.String(), nil
+}
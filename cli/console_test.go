@@ -0,0 +1,210 @@
+package cli
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseConsole(t *testing.T) {
+	type args struct {
+		input string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []*InputParser
+		wantErr bool
+	}{
+		{
+			"ParseConsoleSucceedsWithSingleRequest",
+			args{
+				"GET /",
+			},
+			[]*InputParser{
+				{"GET", "/", nil, nil},
+			},
+			false,
+		},
+		{
+			"ParseConsoleSucceedsWithMultiLineBody",
+			args{
+				`GET /idx/_search
+{
+  "query": {
+    "match": {
+      "message": "hello   world"
+    }
+  }
+}`,
+			},
+			[]*InputParser{
+				{"GET", "/idx/_search", nil, strings.NewReader(`{
+  "query": {
+    "match": {
+      "message": "hello   world"
+    }
+  }
+}`)},
+			},
+			false,
+		},
+		{
+			"ParseConsoleSucceedsWithMultipleRequestsAndComments",
+			args{
+				`# Create the index
+PUT myindex
+{
+  // Single shard
+  "settings": {"number_of_shards": 1}
+}
+
+// Check it exists
+HEAD myindex
+get _cat/indices
+`,
+			},
+			[]*InputParser{
+				{"PUT", "/myindex", nil, strings.NewReader(`{
+  "settings": {"number_of_shards": 1}
+}`)},
+				{"HEAD", "/myindex", nil, nil},
+				{"GET", "/_cat/indices", nil, nil},
+			},
+			false,
+		},
+		{
+			"ParseConsoleSucceedsWithNDJSONBody",
+			args{
+				`POST _bulk
+{"index": {"_index": "test", "_id": "1"}}
+{
+  "field1": "value1"
+}
+{"delete": {"_index": "test", "_id": "2"}}
+`,
+			},
+			[]*InputParser{
+				{"POST", "/_bulk", nil, strings.NewReader(`{"index":{"_index":"test","_id":"1"}}
+{"field1":"value1"}
+{"delete":{"_index":"test","_id":"2"}}
+`)},
+			},
+			false,
+		},
+		{
+			"ParseConsoleSucceedsWithInlineBody",
+			args{
+				`PUT myindex {"settings": {"number_of_shards": 1}}`,
+			},
+			[]*InputParser{
+				{"PUT", "/myindex", nil, strings.NewReader(`{"settings": {"number_of_shards": 1}}`)},
+			},
+			false,
+		},
+		{
+			"ParseConsoleSucceedsWithBracesInStrings",
+			args{
+				`POST myindex/_doc
+{
+  "message": "}} \" {"
+}`,
+			},
+			[]*InputParser{
+				{"POST", "/myindex/_doc", nil, strings.NewReader(`{
+  "message": "}} \" {"
+}`)},
+			},
+			false,
+		},
+		{
+			"ParseConsoleSucceedsWithEmptyInput",
+			args{
+				"# Nothing to see here\n\n",
+			},
+			nil,
+			false,
+		},
+		{
+			"ParseConsoleFailsWhenBodyHasNoRequest",
+			args{
+				`{"query": {"match_all": {}}}`,
+			},
+			nil,
+			true,
+		},
+		{
+			"ParseConsoleFailsWhenMethodIsInvalid",
+			args{
+				"GET /\nWAT /",
+			},
+			nil,
+			true,
+		},
+		{
+			"ParseConsoleFailsWhenBodyIsUnterminated",
+			args{
+				"GET /_search\n{\n  \"query\": {\n",
+			},
+			nil,
+			true,
+		},
+		{
+			"ParseConsoleFailsWhenBodyIsInvalid",
+			args{
+				"GET /_search\n{\"query\": }\n",
+			},
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseConsole(strings.NewReader(tt.args.input))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseConsole() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseConsole() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConsoleParser_Pending(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  bool
+	}{
+		{
+			"PendingIsFalseWithoutBody",
+			[]string{"GET /"},
+			false,
+		},
+		{
+			"PendingIsTrueWhenBodyIsOpen",
+			[]string{"GET /_search {", `  "query": {`},
+			true,
+		},
+		{
+			"PendingIsFalseWhenBodyIsClosed",
+			[]string{"GET /_search {", `  "query": {"match_all": {}}`, "}"},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewConsoleParser()
+			for _, line := range tt.lines {
+				if err := p.ParseLine(line); err != nil {
+					t.Fatalf("ConsoleParser.ParseLine() error = %v", err)
+				}
+			}
+			if got := p.Pending(); got != tt.want {
+				t.Errorf("ConsoleParser.Pending() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
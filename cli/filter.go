@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/jmespath/go-jmespath"
+	"gopkg.in/yaml.v2"
+)
+
+// filterSeparator separates the request from the filter expression in the REPL
+const filterSeparator = "| filter "
+
+// SplitFilter splits a "METHOD url [body] | filter <expression>" REPL line
+// into the request and the filter expression, which is empty when the line
+// has no filter. The separator is only looked for outside of the JSON body,
+// so it can be part of its strings.
+func SplitFilter(line string) (string, string) {
+	var depth int
+	var inString, escaped bool
+	for i := 0; i < len(line); i++ {
+		var c = line[i]
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{' || c == '[':
+			depth++
+		case c == '}' || c == ']':
+			depth--
+		case depth <= 0 && strings.HasPrefix(line[i:], filterSeparator):
+			return strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+len(filterSeparator):])
+		}
+	}
+	return line, ""
+}
+
+// compileQuery validates the JMESPath expression
+func compileQuery(query string) (*jmespath.JMESPath, error) {
+	expression, err := jmespath.Compile(query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query \"%s\": %s", query, err)
+	}
+	return expression, nil
+}
+
+// applyQuery applies the JMESPath expression to the JSON content, returning
+// the result encoded as JSON. The object keys are written in the order they
+// appear in the response, and the integers which can't be represented exactly
+// as float64 (i.e. large IDs) are kept as they were received.
+func applyQuery(expression *jmespath.JMESPath, content []byte) ([]byte, error) {
+	var decoder = json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+
+	ordered, err := decodeOrdered(decoder)
+	if err == nil {
+		// Multiple JSON documents (i.e. NDJSON) can't be queried
+		if _, tokenErr := decoder.Token(); tokenErr != io.EOF {
+			err = errors.New("invalid character after top-level value")
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to apply the query, the response is not JSON: %s", err)
+	}
+
+	var order = make(map[string]int)
+	result, err := expression.Search(queryData(ordered, order))
+	if err != nil {
+		return nil, fmt.Errorf("unable to apply the query: %s", err)
+	}
+
+	var output bytes.Buffer
+	if err := encodeQueryResult(&output, result, order); err != nil {
+		return nil, err
+	}
+	return output.Bytes(), nil
+}
+
+// queryData converts the value returned by decodeOrdered into the types used
+// by JMESPath, recording the order in which the object keys first appear
+func queryData(value interface{}, order map[string]int) interface{} {
+	switch v := value.(type) {
+	case yaml.MapSlice:
+		var object = make(map[string]interface{}, len(v))
+		for _, item := range v {
+			var key = fmt.Sprint(item.Key)
+			if _, ok := order[key]; !ok {
+				order[key] = len(order)
+			}
+			object[key] = queryData(item.Value, order)
+		}
+		return object
+	case []interface{}:
+		for i := range v {
+			v[i] = queryData(v[i], order)
+		}
+		return v
+	case int64:
+		// JMESPath compares and aggregates numbers as float64
+		if v <= 1<<53 && v >= -1<<53 {
+			return float64(v)
+		}
+	}
+	return value
+}
+
+// encodeQueryResult encodes the result as JSON without escaping HTML, writing
+// the object keys in the order they appear in the response. The keys created
+// by the query are written last, sorted by name.
+func encodeQueryResult(output *bytes.Buffer, value interface{}, order map[string]int) error {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		var keys = make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			oi, iok := order[keys[i]]
+			oj, jok := order[keys[j]]
+			if iok && jok {
+				return oi < oj
+			}
+			if iok != jok {
+				return iok
+			}
+			return keys[i] < keys[j]
+		})
+
+		output.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				output.WriteByte(',')
+			}
+			if err := encodeQueryResult(output, key, order); err != nil {
+				return err
+			}
+			output.WriteByte(':')
+			if err := encodeQueryResult(output, v[key], order); err != nil {
+				return err
+			}
+		}
+		output.WriteByte('}')
+		return nil
+	case []interface{}:
+		output.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				output.WriteByte(',')
+			}
+			if err := encodeQueryResult(output, item, order); err != nil {
+				return err
+			}
+		}
+		output.WriteByte(']')
+		return nil
+	}
+
+	var encoded bytes.Buffer
+	var encoder = json.NewEncoder(&encoded)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return err
+	}
+	output.Write(bytes.TrimSuffix(encoded.Bytes(), []byte("\n")))
+	return nil
+}
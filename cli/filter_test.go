@@ -0,0 +1,179 @@
+package cli
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSplitFilter(t *testing.T) {
+	tests := []struct {
+		name       string
+		line       string
+		wantLine   string
+		wantFilter string
+	}{
+		{
+			"SplitFilterWithoutFilter",
+			"GET /_search",
+			"GET /_search",
+			"",
+		},
+		{
+			"SplitFilterWithFilter",
+			"GET /_search | filter hits.hits[]._id",
+			"GET /_search",
+			"hits.hits[]._id",
+		},
+		{
+			"SplitFilterWithBodyAndFilter",
+			`GET /_search {"size": 1} | filter hits.total`,
+			`GET /_search {"size": 1}`,
+			"hits.total",
+		},
+		{
+			"SplitFilterIgnoresTheSeparatorInTheBody",
+			`GET /_search {"query":{"query_string":{"query":"a | filter b"}}}`,
+			`GET /_search {"query":{"query_string":{"query":"a | filter b"}}}`,
+			"",
+		},
+		{
+			"SplitFilterAfterABodyWithTheSeparator",
+			`GET /_search {"query":{"query_string":{"query":"a \" | filter b"}}} | filter hits.total`,
+			`GET /_search {"query":{"query_string":{"query":"a \" | filter b"}}}`,
+			"hits.total",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotLine, gotFilter := SplitFilter(tt.line)
+			if gotLine != tt.wantLine {
+				t.Errorf("SplitFilter() line = %v, want %v", gotLine, tt.wantLine)
+			}
+			if gotFilter != tt.wantFilter {
+				t.Errorf("SplitFilter() filter = %v, want %v", gotFilter, tt.wantFilter)
+			}
+		})
+	}
+}
+
+func TestNewFormatterWithQuery(t *testing.T) {
+	var search = `{"took": 1, "hits": {"total": 2, "hits": [{"_id": "a", "_source": {"user": "marc"}}, {"_id": "b", "_source": {"user": "elastic"}}]}}`
+	tests := []struct {
+		name       string
+		opts       FormatOptions
+		content    string
+		want       string
+		wantErr    bool
+		wantFmtErr bool
+	}{
+		{
+			"QueryIsAppliedBeforeFormatting",
+			FormatOptions{Query: "hits.hits[]._id"},
+			search,
+			encodeData(`["a","b"]`),
+			false,
+			false,
+		},
+		{
+			"QueryIsAppliedBeforeYAMLFormatting",
+			FormatOptions{Output: "yaml", Query: "hits.hits[?_id=='b']._source | [0]"},
+			search,
+			"user: elastic\n",
+			false,
+			false,
+		},
+		{
+			"QueryIsAppliedBeforeTableFormatting",
+			FormatOptions{Output: "table", Query: "hits.hits[]._source"},
+			search,
+			"user\nmarc\nelastic\n",
+			false,
+			false,
+		},
+		{
+			"QueryKeepsTheKeyOrder",
+			FormatOptions{Output: "yaml", Query: "hits.hits[0]"},
+			search,
+			"_id: a\n_source:\n  user: marc\n",
+			false,
+			false,
+		},
+		{
+			"QueryKeepsLargeIntegers",
+			FormatOptions{Output: "json-compact", Query: "[doc.id, doc.size]"},
+			`{"doc": {"id": 9007199254740993, "size": 2.5}}`,
+			"[9007199254740993,2.5]\n",
+			false,
+			false,
+		},
+		{
+			"QueryKeepsTheKeyOrderOfEveryObject",
+			FormatOptions{Output: "json-compact", Query: "{b: z.b, docs: docs}"},
+			`{"z": {"b": 1}, "docs": [{"y": 1, "x": 2}, {"y": 3, "x": 4}]}`,
+			"{\"b\":1,\"docs\":[{\"y\":1,\"x\":2},{\"y\":3,\"x\":4}]}\n",
+			false,
+			false,
+		},
+		{
+			"QueryDoesNotEscapeHTML",
+			FormatOptions{Output: "json-compact", Query: "query"},
+			`{"query": "<a> & <b>"}`,
+			"\"<a> & <b>\"\n",
+			false,
+			false,
+		},
+		{
+			"QueryComparesNumbers",
+			FormatOptions{Output: "json-compact", Query: "hits.hits[?length(_id) > `0`]._id | length(@)"},
+			search,
+			"2\n",
+			false,
+			false,
+		},
+		{
+			"InvalidQueryFails",
+			FormatOptions{Query: "hits.hits[["},
+			search,
+			"",
+			true,
+			false,
+		},
+		{
+			"QueryFailsWhenResponseIsNotJSON",
+			FormatOptions{Query: "hits"},
+			"green open myindex",
+			"",
+			false,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewFormatter(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewFormatter() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+
+			var output bytes.Buffer
+			err = f(&http.Response{
+				Body:    ioutil.NopCloser(strings.NewReader(tt.content)),
+				Status:  "200 OK",
+				Request: &http.Request{Method: "GET"},
+			}, false, false, &output)
+			if (err != nil) != tt.wantFmtErr {
+				t.Errorf("Formatter error = %v, wantErr %v", err, tt.wantFmtErr)
+				return
+			}
+			if output.String() != tt.want {
+				t.Errorf("Formatter output = %v, want = %v", output.String(), tt.want)
+			}
+		})
+	}
+}
@@ -7,24 +7,121 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"sort"
 	"strings"
+	"time"
+
+	"github.com/jmespath/go-jmespath"
+	"github.com/marclop/elasticsearch-cli/client"
+	"github.com/marclop/elasticsearch-cli/utils"
+	"gopkg.in/yaml.v2"
 )
 
+// The labels followed by a value are padded to the same width, so the values
+// are aligned. The section labels (i.e. headersFormat) aren't padded.
 const (
 	methodFormat      = "Method:      "
 	urlFormat         = "URL:         "
 	responseFormat    = "Response:    "
 	contentTypeFormat = "Content-Type:"
+	timeFormat        = "Time:        "
+	sizeFormat        = "Size:        "
+	headersFormat     = "Headers:"
+	requestFormat     = "Request headers:"
+	requestSizeFormat = "Request size:"
+	nodeFormat        = "Node:        "
 )
 
-// Format formats the HTTPResponse to the output io.Writer
+// DefaultOutput is the output format used when none is specified
+const DefaultOutput = "json"
+
+// OutputFormats is the list of supported output formats
+var OutputFormats = []string{
+	"json",
+	"json-compact",
+	"yaml",
+	"table",
+	"raw",
+}
+
+// bodyFormatter writes the response content to the output in a specific format
+type bodyFormatter func(content []byte, opts FormatOptions, output io.Writer) error
+
+var bodyFormatters = map[string]bodyFormatter{
+	"json":         formatJSON,
+	"json-compact": formatCompactJSON,
+	"yaml":         formatYAML,
+	"table":        formatTable,
+	"raw":          formatRaw,
+}
+
+// FormatOptions controls how the responses are formatted
+type FormatOptions struct {
+	// Output is the name of the output format, one of OutputFormats
+	Output string
+	// Columns to display in the table output, all of them when empty
+	Columns []string
+	// Query is a JMESPath expression which is applied to the JSON response
+	// before formatting it
+	Query string
+	// Color enables the syntax highlighting of the JSON output
+	Color bool
+	// Theme contains the colors used when Color is enabled
+	Theme Theme
+}
+
+// NewFormatter returns a function which formats the HTTPResponse according
+// to the specified options
+func NewFormatter(opts FormatOptions) (func(*http.Response, bool, bool, io.Writer) error, error) {
+	if opts.Output == "" {
+		opts.Output = DefaultOutput
+	}
+
+	bodyFormatter, ok := bodyFormatters[opts.Output]
+	if !ok {
+		return nil, fmt.Errorf("output \"%s\" is not supported, must be one of %s",
+			opts.Output, strings.Join(OutputFormats, ", "),
+		)
+	}
+
+	// The outputs which can't be streamed read the whole response before
+	// formatting it, like the query does
+	f, ok := streamFormatters[opts.Output]
+	if !ok {
+		f = bufferBody(bodyFormatter)
+	}
+
+	if opts.Query != "" {
+		expression, err := compileQuery(opts.Query)
+		if err != nil {
+			return nil, err
+		}
+		f = bufferBody(filterBody(expression, bodyFormatter))
+	}
+
+	return func(input *http.Response, verbose bool, interactive bool, output io.Writer) error {
+		return format(input, verbose, interactive, output, opts, f)
+	}, nil
+}
+
+// Format formats the HTTPResponse to the output io.Writer as indented JSON
 func Format(input *http.Response, verbose bool, interactive bool, output io.Writer) error {
-	content, err := ioutil.ReadAll(input.Body)
-	if err != nil {
-		return err
+	return format(input, verbose, interactive, output, FormatOptions{}, streamJSON)
+}
+
+// format writes the request and response details followed by the body, which
+// is streamed to the output unless its size needs to be printed first
+func format(input *http.Response, verbose bool, interactive bool, output io.Writer, opts FormatOptions, f streamFormatter) error {
+	var body io.Reader = input.Body
+	var size int
+	if verbose {
+		content, err := ioutil.ReadAll(input.Body)
+		if err != nil {
+			return err
+		}
+		size = len(content)
+		body = bytes.NewReader(content)
 	}
-	// Removes any extra spaces the body might be carrying
-	content = []byte(strings.TrimSpace(string(content)))
 
 	var headers = new(bytes.Buffer)
 	if interactive || verbose {
@@ -32,17 +129,35 @@ func Format(input *http.Response, verbose bool, interactive bool, output io.Writ
 			fmt.Sprintln(methodFormat, strings.ToUpper(input.Request.Method)),
 		)
 		headers.WriteString(
-			fmt.Sprintln(urlFormat, strings.ToLower(input.Request.URL.Path)),
+			fmt.Sprintln(urlFormat, input.Request.URL.RequestURI()),
 		)
 	}
 
+	// The node which served the request, when multiple hosts are used
+	if node, ok := client.Node(input); verbose && ok {
+		headers.WriteString(fmt.Sprintln(nodeFormat, node))
+	}
+
+	if verbose && len(input.Request.Header) > 0 {
+		headers.WriteString(fmt.Sprintln(requestFormat))
+		writeHeaders(headers, input.Request.Header)
+	}
+
+	if size, compressed, ok := client.RequestSize(input); verbose && ok {
+		headers.WriteString(fmt.Sprintln(requestSizeFormat, sizeText(size, compressed, true)))
+	}
+
 	if verbose || input.Request.Method == "HEAD" {
 		headers.WriteString(
 			fmt.Sprintln(responseFormat, input.Status),
 		)
-		headers.WriteString(
-			fmt.Sprintln(contentTypeFormat, input.Request.Header["Content-Type"][0]),
-		)
+		if contentType := input.Header.Get("Content-Type"); contentType != "" {
+			headers.WriteString(fmt.Sprintln(contentTypeFormat, contentType))
+		}
+	}
+
+	if verbose {
+		writeResponseDetails(headers, input, size)
 	}
 
 	// Print the headers
@@ -52,16 +167,126 @@ func Format(input *http.Response, verbose bool, interactive bool, output io.Writ
 	}
 
 	// Print the response content
+	return f(body, opts, output)
+}
+
+// writeResponseDetails writes the time it took to obtain the response, its
+// size (and compressed size) and all of the response headers sorted by name.
+func writeResponseDetails(headers *bytes.Buffer, input *http.Response, size int) {
+	if elapsed, ok := client.Elapsed(input); ok {
+		headers.WriteString(fmt.Sprintln(timeFormat, roundElapsed(elapsed)))
+	}
+	compressed, ok := client.CompressedSize(input)
+	headers.WriteString(fmt.Sprintln(sizeFormat, sizeText(int64(size), compressed, ok)))
+
+	if len(input.Header) > 0 {
+		headers.WriteString(fmt.Sprintln(headersFormat))
+		writeHeaders(headers, input.Header)
+	}
+}
+
+// sizeText returns the size in bytes, followed by the compressed size
+func sizeText(size, compressed int64, isCompressed bool) string {
+	if !isCompressed {
+		return fmt.Sprint(size, " bytes")
+	}
+	return fmt.Sprint(size, " bytes (", compressed, " bytes compressed)")
+}
+
+// writeHeaders writes the headers sorted by name, redacting the credentials
+func writeHeaders(headers *bytes.Buffer, header http.Header) {
+	var names = make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, value := range header[name] {
+			headers.WriteString(fmt.Sprintf("  %s: %s\n", name, RedactHeader(name, value)))
+		}
+	}
+}
+
+// roundElapsed rounds the duration to milliseconds, or to tens of
+// microseconds when it's less than a second
+func roundElapsed(d time.Duration) time.Duration {
+	if d < time.Second {
+		return d.Round(10 * time.Microsecond)
+	}
+	return d.Round(time.Millisecond)
+}
+
+// filterBody returns a bodyFormatter which applies the JMESPath expression to
+// the content before formatting it with f
+func filterBody(expression *jmespath.JMESPath, f bodyFormatter) bodyFormatter {
+	return func(content []byte, opts FormatOptions, output io.Writer) error {
+		filtered, err := applyQuery(expression, content)
+		if err != nil {
+			return err
+		}
+		return f(filtered, opts, output)
+	}
+}
+
+// formatJSON indents the JSON content, printing it as is when it's not JSON
+func formatJSON(content []byte, opts FormatOptions, output io.Writer) error {
 	var payload bytes.Buffer
-	err = json.Indent(&payload, content, "", "  ")
+	if err := json.Indent(&payload, content, "", "  "); err != nil {
+		return formatRaw(content, opts, output)
+	}
+
+	return writeJSON(payload.Bytes(), opts, output)
+}
+
+// formatCompactJSON prints the JSON content in a single line, printing it as
+// is when it's not JSON
+func formatCompactJSON(content []byte, opts FormatOptions, output io.Writer) error {
+	var payload bytes.Buffer
+	if err := json.Compact(&payload, content); err != nil {
+		return formatRaw(content, opts, output)
+	}
+
+	return writeJSON(payload.Bytes(), opts, output)
+}
+
+// writeJSON writes the formatted JSON payload, colourising it when enabled
+func writeJSON(payload []byte, opts FormatOptions, output io.Writer) error {
+	if opts.Color {
+		payload = colorizeJSON(payload, opts.Theme)
+	}
+
+	_, err := io.WriteString(output, utils.ConcatStrings(string(payload), "\n"))
+	return err
+}
+
+// formatYAML converts the JSON content to YAML keeping the order of the keys,
+// printing it as is when it's not JSON
+func formatYAML(content []byte, opts FormatOptions, output io.Writer) error {
+	var decoder = json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+
+	value, err := decodeOrdered(decoder)
 	if err != nil {
-		payload.Write(content)
+		return formatRaw(content, opts, output)
 	}
 
-	if payload.String() != "" {
-		payload.WriteString("\n")
-		payload.WriteTo(output)
+	// Multiple JSON documents (i.e. NDJSON) can't be represented as YAML
+	if _, err := decoder.Token(); err != io.EOF {
+		return formatRaw(content, opts, output)
 	}
 
-	return nil
+	payload, err := yaml.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	_, err = output.Write(payload)
+	return err
+}
+
+// formatRaw prints the content as it was received
+func formatRaw(content []byte, opts FormatOptions, output io.Writer) error {
+	_, err := io.WriteString(output, utils.ConcatStrings(string(content), "\n"))
+	return err
 }
@@ -3,6 +3,7 @@ package cli
 import (
 	"bytes"
 	"encoding/json"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
@@ -17,6 +18,14 @@ func encodeData(p string) string {
 	return v.String()
 }
 
+// compressedBody is a response body decoded by the client
+type compressedBody struct {
+	io.ReadCloser
+	compressed int64
+}
+
+func (b *compressedBody) CompressedSize() int64 { return b.compressed }
+
 func TestFormat(t *testing.T) {
 	type args struct {
 		input       *http.Response
@@ -68,11 +77,11 @@ func TestFormat(t *testing.T) {
 				&http.Response{
 					Body:   ioutil.NopCloser(strings.NewReader("")),
 					Status: "200 OK",
+					Header: http.Header{
+						"Content-Type": []string{"application/json"},
+					},
 					Request: &http.Request{
 						Method: "HEAD",
-						Header: http.Header{
-							"Content-Type": []string{"application/json"},
-						},
 						URL: &url.URL{
 							Path: "/",
 						},
@@ -93,11 +102,11 @@ Content-Type: application/json
 				&http.Response{
 					Body:   ioutil.NopCloser(strings.NewReader("")),
 					Status: "200 OK",
+					Header: http.Header{
+						"Content-Type": []string{"application/json"},
+					},
 					Request: &http.Request{
 						Method: "HEAD",
-						Header: http.Header{
-							"Content-Type": []string{"application/json"},
-						},
 						URL: &url.URL{
 							Path: "/",
 						},
@@ -111,8 +120,81 @@ Content-Type: application/json
 URL:          /
 Response:     200 OK
 Content-Type: application/json
+Size:         0 bytes
+Headers:
+  Content-Type: application/json
 
 `,
+		},
+		{
+			"FormatSucceedsWithVerboseResponseHeaders",
+			args{
+				&http.Response{
+					Body:   ioutil.NopCloser(strings.NewReader(`{"a":"b"}` + "\n")),
+					Status: "200 OK",
+					Header: http.Header{
+						"Content-Type": []string{"application/json; charset=UTF-8"},
+						"X-Opaque-Id":  []string{"my-request"},
+						"Warning": []string{
+							`299 Elasticsearch-6.0.0-8f0685b "first deprecation"`,
+							`299 Elasticsearch-6.0.0-8f0685b "second deprecation"`,
+						},
+					},
+					Request: &http.Request{
+						Method: "GET",
+						Header: http.Header{
+							"Authorization": []string{"ApiKey aWQ6a2V5"},
+							"Content-Type":  []string{"application/json"},
+						},
+						URL: &url.URL{
+							Host: "node1:9200",
+							Path: "/_search",
+						},
+					},
+				},
+				true,
+				false,
+				&bytes.Buffer{},
+			},
+			`Method:       GET
+URL:          /_search
+Request headers:
+  Authorization: ApiKey [REDACTED]
+  Content-Type: application/json
+Response:     200 OK
+Content-Type: application/json; charset=UTF-8
+Size:         10 bytes
+Headers:
+  Content-Type: application/json; charset=UTF-8
+  Warning: 299 Elasticsearch-6.0.0-8f0685b "first deprecation"
+  Warning: 299 Elasticsearch-6.0.0-8f0685b "second deprecation"
+  X-Opaque-Id: my-request
+
+` + encodeData(`{"a":"b"}`),
+		},
+		{
+			"FormatSucceedsWithVerboseCompressedResponse",
+			args{
+				&http.Response{
+					Body:   &compressedBody{ioutil.NopCloser(strings.NewReader(`{"a":"b"}`)), 29},
+					Status: "200 OK",
+					Request: &http.Request{
+						Method: "GET",
+						URL: &url.URL{
+							Path: "/_search",
+						},
+					},
+				},
+				true,
+				false,
+				&bytes.Buffer{},
+			},
+			`Method:       GET
+URL:          /_search
+Response:     200 OK
+Size:         9 bytes (29 bytes compressed)
+
+` + encodeData(`{"a":"b"}`),
 		},
 		{
 			"FormatSucceedsWithInteractive",
@@ -120,11 +202,11 @@ Content-Type: application/json
 				&http.Response{
 					Body:   ioutil.NopCloser(strings.NewReader("")),
 					Status: "200 OK",
+					Header: http.Header{
+						"Content-Type": []string{"application/json"},
+					},
 					Request: &http.Request{
 						Method: "HEAD",
-						Header: http.Header{
-							"Content-Type": []string{"application/json"},
-						},
 						URL: &url.URL{
 							Path: "/",
 						},
@@ -151,3 +233,104 @@ Content-Type: application/json
 		})
 	}
 }
+
+func TestFormatLabelsAreAligned(t *testing.T) {
+	for _, label := range []string{
+		methodFormat, urlFormat, responseFormat, contentTypeFormat,
+		timeFormat, sizeFormat, requestSizeFormat, nodeFormat,
+	} {
+		if len(label) != len(methodFormat) {
+			t.Errorf("label %q width = %d, want %d", label, len(label), len(methodFormat))
+		}
+	}
+}
+
+func TestNewFormatter(t *testing.T) {
+	var content = `{"cluster_name":"elasticsearch","version":{"number":"5.6.0","build_snapshot":false},"nodes":[1,2.5]}`
+	tests := []struct {
+		name    string
+		opts    FormatOptions
+		content string
+		want    string
+		wantErr bool
+	}{
+		{
+			"NewFormatterDefaultsToJSON",
+			FormatOptions{},
+			content,
+			encodeData(content),
+			false,
+		},
+		{
+			"NewFormatterSucceedsWithCompactJSON",
+			FormatOptions{Output: "json-compact"},
+			`{
+  "cluster_name": "elasticsearch"
+}`,
+			`{"cluster_name":"elasticsearch"}
+`,
+			false,
+		},
+		{
+			"NewFormatterSucceedsWithYAML",
+			FormatOptions{Output: "yaml"},
+			content,
+			`cluster_name: elasticsearch
+version:
+  number: 5.6.0
+  build_snapshot: false
+nodes:
+- 1
+- 2.5
+`,
+			false,
+		},
+		{
+			"NewFormatterSucceedsWithYAMLAndPlainText",
+			FormatOptions{Output: "yaml"},
+			"green open myindex",
+			"green open myindex\n",
+			false,
+		},
+		{
+			"NewFormatterSucceedsWithRaw",
+			FormatOptions{Output: "raw"},
+			`{"a":  "b"}`,
+			`{"a":  "b"}
+`,
+			false,
+		},
+		{
+			"NewFormatterFailsWithUnknownOutput",
+			FormatOptions{Output: "xml"},
+			"",
+			"",
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewFormatter(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewFormatter() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+
+			var output bytes.Buffer
+			err = f(&http.Response{
+				Body:    ioutil.NopCloser(strings.NewReader(tt.content)),
+				Status:  "200 OK",
+				Request: &http.Request{Method: "GET"},
+			}, false, false, &output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output.String() != tt.want {
+				t.Errorf("NewFormatter() = %v, want = %v", output.String(), tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,8 @@ package cli
 
 import (
 	"fmt"
+	"io"
+	"net/url"
 	"strings"
 
 	"github.com/marclop/elasticsearch-cli/utils"
@@ -20,11 +22,14 @@ var SupportedMethods = []string{
 }
 
 // InputParser is the struct that parses the input into something usable by the
-// application
+// application. The Path is kept verbatim (including any escaped characters),
+// while the query string parameters are parsed into Query. The Body is nil
+// when the request has none.
 type InputParser struct {
 	Method string
-	URL    string
-	Body   string
+	Path   string
+	Query  url.Values
+	Body   io.Reader
 }
 
 // NewInputParser initializes the parser and validates the input
@@ -35,16 +40,19 @@ func NewInputParser(input []string) (*InputParser, error) {
 
 	var inputParser = &InputParser{
 		Method: strings.ToUpper(input[0]),
-		URL:    defaultURL,
-		Body:   "",
+		Path:   defaultURL,
 	}
 
 	if len(input) > 1 {
-		inputParser.URL = strings.ToLower(input[1])
+		if err := inputParser.parseURL(input[1]); err != nil {
+			return nil, err
+		}
 	}
 
 	if len(input) > 2 {
-		inputParser.Body = strings.Join(input[2:], "")
+		if body := strings.Join(input[2:], " "); body != "" {
+			inputParser.Body = strings.NewReader(body)
+		}
 	}
 
 	err := inputParser.Validate()
@@ -64,8 +72,41 @@ func (p *InputParser) Validate() error {
 	return nil
 }
 
+// URL returns the relative URL of the request with its query string encoded
+func (p *InputParser) URL() string {
+	if len(p.Query) == 0 {
+		return p.Path
+	}
+	return utils.ConcatStrings(p.Path, "?", p.Query.Encode())
+}
+
+// parseURL splits the relative URL into its path and query string parameters
+func (p *InputParser) parseURL(rawURL string) error {
+	var rawQuery string
+	if i := strings.Index(rawURL, "?"); i >= 0 {
+		rawURL, rawQuery = rawURL[:i], rawURL[i+1:]
+	}
+
+	if _, err := url.PathUnescape(rawURL); err != nil {
+		return fmt.Errorf("invalid URL path \"%s\": %s", rawURL, err)
+	}
+	p.Path = rawURL
+
+	if rawQuery != "" {
+		// url.ParseQuery rejects the semicolons, which are kept as part of
+		// the values (i.e. q=a;b) like they're sent to Elasticsearch
+		query, err := url.ParseQuery(strings.Replace(rawQuery, ";", "%3B", -1))
+		if err != nil {
+			return fmt.Errorf("invalid query string \"%s\": %s", rawQuery, err)
+		}
+		p.Query = query
+	}
+
+	return nil
+}
+
 func (p *InputParser) ensureURLIsPrefixed() {
-	if !strings.HasPrefix(p.URL, "/") {
-		p.URL = utils.ConcatStrings("/", p.URL)
+	if !strings.HasPrefix(p.Path, "/") {
+		p.Path = utils.ConcatStrings("/", p.Path)
 	}
 }
@@ -1,7 +1,10 @@
 package cli
 
 import (
+	"io"
+	"net/url"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -27,7 +30,8 @@ func TestNewInputParser(t *testing.T) {
 			&InputParser{
 				"GET",
 				"/",
-				"",
+				nil,
+				nil,
 			},
 			false,
 		},
@@ -43,7 +47,8 @@ func TestNewInputParser(t *testing.T) {
 			&InputParser{
 				"GET",
 				"/",
-				"",
+				nil,
+				nil,
 			},
 			false,
 		},
@@ -71,6 +76,126 @@ func TestNewInputParser(t *testing.T) {
 			nil,
 			true,
 		},
+		{
+			"NewParserPreservesDocumentIDCase",
+			args{
+				[]string{
+					"get",
+					"myIndex/_doc/AbC-123_xYz",
+				},
+			},
+			&InputParser{
+				"GET",
+				"/myIndex/_doc/AbC-123_xYz",
+				nil,
+				nil,
+			},
+			false,
+		},
+		{
+			"NewParserPreservesQueryStringValues",
+			args{
+				[]string{
+					"GET",
+					"/_search?q=Field:Value&Scroll=1m",
+				},
+			},
+			&InputParser{
+				"GET",
+				"/_search",
+				url.Values{"q": []string{"Field:Value"}, "Scroll": []string{"1m"}},
+				nil,
+			},
+			false,
+		},
+		{
+			"NewParserPreservesEncodedCharacters",
+			args{
+				[]string{
+					"GET",
+					"/myindex/_doc/my%2Fid%20A?q=message:%22Hello%20World%22",
+				},
+			},
+			&InputParser{
+				"GET",
+				"/myindex/_doc/my%2Fid%20A",
+				url.Values{"q": []string{`message:"Hello World"`}},
+				nil,
+			},
+			false,
+		},
+		{
+			"NewParserSucceedsWithRepeatedParameters",
+			args{
+				[]string{
+					"GET",
+					"_cat/indices?h=index&h=health&v",
+				},
+			},
+			&InputParser{
+				"GET",
+				"/_cat/indices",
+				url.Values{"h": []string{"index", "health"}, "v": []string{""}},
+				nil,
+			},
+			false,
+		},
+		{
+			"NewParserKeepsSemicolonsInQueryStringValues",
+			args{
+				[]string{
+					"GET",
+					"/_search?q=a;b&size=1",
+				},
+			},
+			&InputParser{
+				"GET",
+				"/_search",
+				url.Values{"q": []string{"a;b"}, "size": []string{"1"}},
+				nil,
+			},
+			false,
+		},
+		{
+			"NewParserKeepsWhitespaceInBody",
+			args{
+				[]string{
+					"PUT",
+					"myindex/_doc/1",
+					`{"message":`,
+					`"hello world"}`,
+				},
+			},
+			&InputParser{
+				"PUT",
+				"/myindex/_doc/1",
+				nil,
+				strings.NewReader(`{"message": "hello world"}`),
+			},
+			false,
+		},
+		{
+			"NewParserFailsWhenQueryStringIsInvalid",
+			args{
+				[]string{
+					"GET",
+					"/_search?q=%zz",
+				},
+			},
+			nil,
+			true,
+		},
+		{
+			"NewParserFailsWhenPathIsInvalid",
+			args{
+				[]string{
+					"GET",
+					"/my%zzindex",
+				},
+			},
+			nil,
+			true,
+		},
 		{
 			"NewParserSucceedsWhenIsInteractive",
 			args{
@@ -97,8 +222,8 @@ func TestNewInputParser(t *testing.T) {
 func TestParser_Validate(t *testing.T) {
 	type fields struct {
 		Method string
-		URL    string
-		Body   string
+		Path   string
+		Body   io.Reader
 	}
 	tests := []struct {
 		name    string
@@ -110,7 +235,7 @@ func TestParser_Validate(t *testing.T) {
 			fields{
 				"GET",
 				"/",
-				"",
+				nil,
 			},
 			false,
 		},
@@ -119,7 +244,7 @@ func TestParser_Validate(t *testing.T) {
 			fields{
 				"",
 				"/",
-				"",
+				nil,
 			},
 			true,
 		},
@@ -128,7 +253,7 @@ func TestParser_Validate(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			p := &InputParser{
 				Method: tt.fields.Method,
-				URL:    tt.fields.URL,
+				Path:   tt.fields.Path,
 				Body:   tt.fields.Body,
 			}
 			if err := p.Validate(); (err != nil) != tt.wantErr {
@@ -137,3 +262,40 @@ func TestParser_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestInputParser_URL(t *testing.T) {
+	tests := []struct {
+		name  string
+		input *InputParser
+		want  string
+	}{
+		{
+			"URLWithoutQuery",
+			&InputParser{Path: "/myIndex/_doc/ID"},
+			"/myIndex/_doc/ID",
+		},
+		{
+			"URLWithEncodedQuery",
+			&InputParser{
+				Path:  "/_search",
+				Query: url.Values{"q": []string{"Field:Some Value"}, "size": []string{"1"}},
+			},
+			"/_search?q=Field%3ASome+Value&size=1",
+		},
+		{
+			"URLWithSemicolonInQuery",
+			&InputParser{
+				Path:  "/_search",
+				Query: url.Values{"q": []string{"a;b"}},
+			},
+			"/_search?q=a%3Bb",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.input.URL(); got != tt.want {
+				t.Errorf("InputParser.URL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
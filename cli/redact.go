@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"net/http"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveHeaders contain credentials and are redacted in the output
+var sensitiveHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"X-Amz-Security-Token",
+}
+
+// RedactHeader returns the header value with its credentials redacted, the
+// authentication scheme (i.e. Basic or ApiKey) is kept.
+func RedactHeader(name, value string) string {
+	var sensitive bool
+	for _, header := range sensitiveHeaders {
+		if http.CanonicalHeaderKey(name) == header {
+			sensitive = true
+			break
+		}
+	}
+
+	if !sensitive {
+		return value
+	}
+
+	if i := strings.IndexByte(value, ' '); i > 0 {
+		return value[:i+1] + redacted
+	}
+	return redacted
+}
@@ -0,0 +1,244 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/ioutil"
+)
+
+// streamFormatter writes the response body to the output as it's read, so
+// large responses aren't kept in memory
+type streamFormatter func(body io.Reader, opts FormatOptions, output io.Writer) error
+
+var streamFormatters = map[string]streamFormatter{
+	"json": streamJSON,
+	"raw":  streamRaw,
+}
+
+// bufferBody returns a streamFormatter which reads the whole body before
+// formatting it with f, for the formatters which need all of the content
+func bufferBody(f bodyFormatter) streamFormatter {
+	return func(body io.Reader, opts FormatOptions, output io.Writer) error {
+		content, err := ioutil.ReadAll(body)
+		if err != nil {
+			return err
+		}
+
+		// Removes any extra spaces the body might be carrying
+		content = bytes.TrimSpace(content)
+		if len(content) == 0 {
+			return nil
+		}
+		return f(content, opts, output)
+	}
+}
+
+// streamJSON indents the JSON body as it's read, printing it as is when it
+// isn't a JSON object or array
+func streamJSON(body io.Reader, opts FormatOptions, output io.Writer) error {
+	var r = bufio.NewReader(body)
+	first, err := skipSpace(r)
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if first != '{' && first != '[' {
+		return copyTrimmed(r, output)
+	}
+
+	var dst = output
+	var colored *colorWriter
+	if opts.Color {
+		colored = &colorWriter{output: output, theme: opts.Theme}
+		dst = colored
+	}
+
+	var w = bufio.NewWriter(dst)
+	if err := indentJSON(w, r); err != nil {
+		return err
+	}
+	w.WriteByte('\n')
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if colored != nil {
+		return colored.Flush()
+	}
+	return nil
+}
+
+// streamRaw prints the body as it was received, without the surrounding
+// whitespace
+func streamRaw(body io.Reader, opts FormatOptions, output io.Writer) error {
+	var r = bufio.NewReader(body)
+	if _, err := skipSpace(r); err == io.EOF {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return copyTrimmed(r, output)
+}
+
+// indentJSON copies the JSON to w indented with two spaces, which is the same
+// output as json.Indent without reading all of it first. Only the whitespace
+// outside of the strings is changed, and multiple documents (i.e. NDJSON) are
+// indented one after the other.
+func indentJSON(w *bufio.Writer, r io.ByteReader) error {
+	var depth int
+	var inString, escaped bool
+	// The newline after an opening bracket is delayed so empty objects and
+	// arrays are kept in a single line
+	var opened bool
+	// ended is set when a document ends, any content after it starts a new one
+	var ended bool
+	for {
+		c, err := r.ReadByte()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if inString {
+			w.WriteByte(c)
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+
+		if isSpace(c) {
+			continue
+		}
+
+		if ended {
+			w.WriteByte('\n')
+			ended = false
+		}
+
+		if opened && c != '}' && c != ']' {
+			writeIndent(w, depth)
+			opened = false
+		}
+
+		switch c {
+		case '{', '[':
+			w.WriteByte(c)
+			depth++
+			opened = true
+		case '}', ']':
+			depth--
+			if opened {
+				opened = false
+			} else {
+				writeIndent(w, depth)
+			}
+			w.WriteByte(c)
+			ended = depth <= 0
+		case ',':
+			w.WriteByte(c)
+			writeIndent(w, depth)
+		case ':':
+			w.WriteString(": ")
+		case '"':
+			inString = true
+			w.WriteByte(c)
+		default:
+			w.WriteByte(c)
+		}
+	}
+}
+
+func writeIndent(w *bufio.Writer, depth int) {
+	w.WriteByte('\n')
+	for i := 0; i < depth; i++ {
+		w.WriteString("  ")
+	}
+}
+
+// skipSpace discards the leading whitespace, returning the first byte which
+// is left unread
+func skipSpace(r *bufio.Reader) (byte, error) {
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if !isSpace(c) {
+			return c, r.UnreadByte()
+		}
+	}
+}
+
+// copyTrimmed copies the content followed by a newline, holding back the
+// whitespace until more content is read so the trailing whitespace is dropped
+func copyTrimmed(r io.Reader, output io.Writer) error {
+	var pending []byte
+	var chunk = make([]byte, 32*1024)
+	for {
+		n, err := r.Read(chunk)
+		if n > 0 {
+			var end = len(bytes.TrimRight(chunk[:n], " \t\r\n"))
+			if end > 0 {
+				if _, werr := output.Write(append(pending, chunk[:end]...)); werr != nil {
+					return werr
+				}
+				pending = pending[:0]
+			}
+			pending = append(pending, chunk[end:n]...)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(output, "\n")
+	return err
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// colorWriter colourises the indented JSON a line at a time, since the lines
+// never split the JSON values
+type colorWriter struct {
+	output io.Writer
+	theme  Theme
+	line   []byte
+}
+
+func (w *colorWriter) Write(p []byte) (int, error) {
+	w.line = append(w.line, p...)
+	if i := bytes.LastIndexByte(w.line, '\n'); i >= 0 {
+		if _, err := w.output.Write(colorizeJSON(w.line[:i+1], w.theme)); err != nil {
+			return 0, err
+		}
+		w.line = append(w.line[:0], w.line[i+1:]...)
+	}
+	return len(p), nil
+}
+
+// Flush writes the last line, when it isn't terminated by a newline
+func (w *colorWriter) Flush() error {
+	if len(w.line) == 0 {
+		return nil
+	}
+	_, err := w.output.Write(colorizeJSON(w.line, w.theme))
+	w.line = w.line[:0]
+	return err
+}
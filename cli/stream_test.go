@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestStreamJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		opts    FormatOptions
+		want    string
+	}{
+		{
+			"StreamJSONIndentsLikeJSONIndent",
+			` {"a":"b, c: [d]","e":[1,2.5e3,-3],"f":{},"g":[],"h":{"i":[{"j":null}]},"k":"\"{"} `,
+			FormatOptions{},
+			encodeData(`{"a":"b, c: [d]","e":[1,2.5e3,-3],"f":{},"g":[],"h":{"i":[{"j":null}]},"k":"\"{"}`),
+		},
+		{
+			"StreamJSONReindentsIndentedJSON",
+			"{\n    \"a\" :  [ 1 ,\n 2 ]\n}\n",
+			FormatOptions{},
+			encodeData(`{"a":[1,2]}`),
+		},
+		{
+			"StreamJSONIndentsEveryDocument",
+			"{\"index\":{}}\n{\"a\":1}\n",
+			FormatOptions{},
+			"{\n  \"index\": {}\n}\n{\n  \"a\": 1\n}\n",
+		},
+		{
+			"StreamJSONPrintsPlainTextAsIs",
+			"  green open myindex  \nyellow open other\n\n",
+			FormatOptions{},
+			"green open myindex  \nyellow open other\n",
+		},
+		{
+			"StreamJSONPrintsNothingWithEmptyBody",
+			" \n",
+			FormatOptions{},
+			"",
+		},
+		{
+			"StreamJSONColorizesEveryLine",
+			`{"a":"b","c":[1,true]}`,
+			FormatOptions{Color: true, Theme: DefaultTheme},
+			string(colorizeJSON([]byte(encodeData(`{"a":"b","c":[1,true]}`)), DefaultTheme)),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output bytes.Buffer
+			// The body is read a byte at a time to split the values across reads
+			if err := streamJSON(iotest.OneByteReader(strings.NewReader(tt.content)), tt.opts, &output); err != nil {
+				t.Fatal(err)
+			}
+			if output.String() != tt.want {
+				t.Errorf("streamJSON() = %q, want %q", output.String(), tt.want)
+			}
+		})
+	}
+}
+
+// writeCounter counts the writes it receives
+type writeCounter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *writeCounter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+func TestStreamJSON_writesIncrementally(t *testing.T) {
+	var docs = make([]string, 2000)
+	for i := range docs {
+		docs[i] = `{"index":"logs","health":"green"}`
+	}
+	var content = "[" + strings.Join(docs, ",") + "]"
+
+	var output writeCounter
+	if err := streamJSON(strings.NewReader(content), FormatOptions{}, &output); err != nil {
+		t.Fatal(err)
+	}
+
+	if output.writes < 10 {
+		t.Errorf("streamJSON() wrote the output in %d writes, want it streamed", output.writes)
+	}
+
+	var want bytes.Buffer
+	json.Indent(&want, []byte(content), "", "  ")
+	want.WriteString("\n")
+	if output.String() != want.String() {
+		t.Error("streamJSON() output differs from json.Indent")
+	}
+}
+
+func TestStreamRaw(t *testing.T) {
+	var output bytes.Buffer
+	var content = "\n {\"a\":  \"b\"} \n\n"
+	if err := streamRaw(iotest.HalfReader(ioutil.NopCloser(strings.NewReader(content))), FormatOptions{}, &output); err != nil {
+		t.Fatal(err)
+	}
+	if want := "{\"a\":  \"b\"}\n"; output.String() != want {
+		t.Errorf("streamRaw() = %q, want %q", output.String(), want)
+	}
+}
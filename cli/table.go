@@ -0,0 +1,239 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v2"
+)
+
+// searchMetaColumns are the hit fields which are displayed before the
+// flattened _source fields of the search responses
+var searchMetaColumns = []string{"_index", "_id"}
+
+// tableRow is a flattened JSON object, which keeps the order of its columns
+type tableRow struct {
+	columns []string
+	values  map[string]string
+}
+
+func newTableRow() *tableRow {
+	return &tableRow{values: make(map[string]string)}
+}
+
+func (r *tableRow) set(column, value string) {
+	if _, ok := r.values[column]; !ok {
+		r.columns = append(r.columns, column)
+	}
+	r.values[column] = value
+}
+
+// formatTable renders the JSON content as an aligned table. Arrays of objects
+// (i.e. _cat APIs with format=json) are rendered as a row per object, search
+// responses as a row per hit with its _source flattened into columns, and any
+// other object as a single row. Nested objects are flattened using dots to
+// join their keys (i.e. docs.count). When the content can't be rendered as a
+// table, it's printed as is.
+func formatTable(content []byte, opts FormatOptions, output io.Writer) error {
+	var decoder = json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+
+	value, err := decodeOrdered(decoder)
+	if err != nil {
+		return formatRaw(content, opts, output)
+	}
+
+	rows, ok := tableRows(value)
+	if !ok {
+		return formatRaw(content, opts, output)
+	}
+
+	var columns = opts.Columns
+	if len(columns) == 0 {
+		columns = tableColumns(rows)
+	}
+
+	if len(columns) == 0 {
+		return nil
+	}
+
+	var table bytes.Buffer
+	var writer = tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, strings.Join(columns, "\t"))
+	for _, row := range rows {
+		var values = make([]string, 0, len(columns))
+		for _, column := range columns {
+			values = append(values, row.values[column])
+		}
+		fmt.Fprintln(writer, strings.Join(values, "\t"))
+	}
+
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+
+	// Remove the padding left behind by empty cells at the end of the rows
+	for _, line := range strings.SplitAfter(table.String(), "\n") {
+		if line == "" {
+			continue
+		}
+		if _, err := io.WriteString(output, strings.TrimRight(line, " \n")+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tableRows returns the table rows for the decoded value, or false if it
+// can't be represented as a table
+func tableRows(value interface{}) ([]*tableRow, bool) {
+	switch v := value.(type) {
+	case []interface{}:
+		var rows = make([]*tableRow, 0, len(v))
+		for _, item := range v {
+			object, ok := item.(yaml.MapSlice)
+			if !ok {
+				return nil, false
+			}
+			var row = newTableRow()
+			flattenObject(row, "", object)
+			rows = append(rows, row)
+		}
+		return rows, true
+	case yaml.MapSlice:
+		if hits, ok := searchHits(v); ok {
+			return hitRows(hits), true
+		}
+		var row = newTableRow()
+		flattenObject(row, "", v)
+		return []*tableRow{row}, true
+	}
+
+	return nil, false
+}
+
+// searchHits returns the hits.hits array when the object is a search response
+func searchHits(object yaml.MapSlice) ([]interface{}, bool) {
+	hits, ok := mapValue(object, "hits").(yaml.MapSlice)
+	if !ok {
+		return nil, false
+	}
+
+	hitList, ok := mapValue(hits, "hits").([]interface{})
+	return hitList, ok
+}
+
+func hitRows(hits []interface{}) []*tableRow {
+	var rows = make([]*tableRow, 0, len(hits))
+	for _, item := range hits {
+		var row = newTableRow()
+		hit, ok := item.(yaml.MapSlice)
+		if !ok {
+			continue
+		}
+
+		for _, column := range searchMetaColumns {
+			row.set(column, stringValue(mapValue(hit, column)))
+		}
+
+		if source, ok := mapValue(hit, "_source").(yaml.MapSlice); ok {
+			flattenObject(row, "", source)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// tableColumns returns all of the columns in the order they first appear
+func tableColumns(rows []*tableRow) []string {
+	var columns []string
+	var seen = make(map[string]bool)
+	for _, row := range rows {
+		for _, column := range row.columns {
+			if !seen[column] {
+				seen[column] = true
+				columns = append(columns, column)
+			}
+		}
+	}
+	return columns
+}
+
+func flattenObject(row *tableRow, prefix string, object yaml.MapSlice) {
+	for _, item := range object {
+		var key = fmt.Sprint(item.Key)
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		if nested, ok := item.Value.(yaml.MapSlice); ok {
+			flattenObject(row, key, nested)
+			continue
+		}
+		row.set(key, stringValue(item.Value))
+	}
+}
+
+func mapValue(object yaml.MapSlice, key string) interface{} {
+	for _, item := range object {
+		if item.Key == key {
+			return item.Value
+		}
+	}
+	return nil
+}
+
+// stringValue returns the cell representation of the value, arrays of scalars
+// are joined by commas while any other arrays or objects are encoded as JSON
+func stringValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.NewReplacer("\t", " ", "\n", " ", "\r", " ").Replace(v)
+	case []interface{}:
+		var values = make([]string, 0, len(v))
+		for _, item := range v {
+			switch item.(type) {
+			case []interface{}, yaml.MapSlice:
+				return jsonValue(v)
+			}
+			values = append(values, stringValue(item))
+		}
+		return strings.Join(values, ",")
+	case yaml.MapSlice:
+		return jsonValue(v)
+	}
+	return fmt.Sprint(value)
+}
+
+// jsonValue encodes the value as JSON, converting the yaml.MapSlice objects
+func jsonValue(value interface{}) string {
+	b, err := json.Marshal(toJSONValue(value))
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(b)
+}
+
+func toJSONValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case yaml.MapSlice:
+		var object = make(map[string]interface{}, len(v))
+		for _, item := range v {
+			object[fmt.Sprint(item.Key)] = toJSONValue(item.Value)
+		}
+		return object
+	case []interface{}:
+		var array = make([]interface{}, 0, len(v))
+		for _, item := range v {
+			array = append(array, toJSONValue(item))
+		}
+		return array
+	}
+	return value
+}
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFormatTable(t *testing.T) {
+	var catIndices = `[
+  {"health": "green", "status": "open", "index": "logs", "pri": "1", "docs.count": "1000"},
+  {"health": "yellow", "status": "open", "index": "metrics-2017", "pri": "5", "docs.count": "42"}
+]`
+	var search = `{
+  "took": 1,
+  "hits": {
+    "total": 2,
+    "hits": [
+      {"_index": "logs", "_id": "1", "_source": {"message": "hello", "user": {"name": "marc", "roles": ["admin", "dev"]}}},
+      {"_index": "logs", "_id": "2", "_source": {"message": "world\ttab", "tags": [{"a": 1}]}}
+    ]
+  }
+}`
+	tests := []struct {
+		name    string
+		content string
+		columns []string
+		want    string
+	}{
+		{
+			"FormatTableSucceedsWithCatAPI",
+			catIndices,
+			nil,
+			`health  status  index         pri  docs.count
+green   open    logs          1    1000
+yellow  open    metrics-2017  5    42
+`,
+		},
+		{
+			"FormatTableSucceedsWithSelectedColumns",
+			catIndices,
+			[]string{"index", "health", "docs.count"},
+			`index         health  docs.count
+logs          green   1000
+metrics-2017  yellow  42
+`,
+		},
+		{
+			"FormatTableSucceedsWithSearchResponse",
+			search,
+			nil,
+			`_index  _id  message    user.name  user.roles  tags
+logs    1    hello      marc       admin,dev
+logs    2    world tab                         [{"a":1}]
+`,
+		},
+		{
+			"FormatTableSucceedsWithSearchResponseAndSelectedColumns",
+			search,
+			[]string{"_id", "user.name"},
+			`_id  user.name
+1    marc
+2
+`,
+		},
+		{
+			"FormatTableSucceedsWithObject",
+			`{"cluster_name": "elasticsearch", "status": "green", "number_of_nodes": 3}`,
+			nil,
+			`cluster_name   status  number_of_nodes
+elasticsearch  green   3
+`,
+		},
+		{
+			"FormatTablePrintsPlainTextAsIs",
+			"green open logs 1 1000",
+			nil,
+			"green open logs 1 1000\n",
+		},
+		{
+			"FormatTablePrintsScalarArraysAsIs",
+			`[1, 2]`,
+			nil,
+			"[1, 2]\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output bytes.Buffer
+			if err := formatTable([]byte(tt.content), FormatOptions{Columns: tt.columns}, &output); err != nil {
+				t.Fatal(err)
+			}
+			if output.String() != tt.want {
+				t.Errorf("formatTable() = \n%v, want = \n%v", output.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Warnings returns the text of the Warning headers of the response, which
+// Elasticsearch uses to communicate deprecations, i.e.:
+// 299 Elasticsearch-6.0.0-8f0685b "Deprecated field [inline] used" "Mon, 01 Jan 2018 00:00:00 GMT"
+func Warnings(res *http.Response) []string {
+	var warnings []string
+	for _, value := range res.Header["Warning"] {
+		warnings = append(warnings, warningText(value))
+	}
+	return warnings
+}
+
+// warningText extracts the quoted warn-text from a Warning header value,
+// returning the value as is when it's not quoted.
+func warningText(value string) string {
+	var start = strings.IndexByte(value, '"')
+	if start < 0 {
+		return value
+	}
+
+	var text strings.Builder
+	for i := start + 1; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			if i+1 < len(value) {
+				i++
+				text.WriteByte(value[i])
+			}
+		case '"':
+			return text.String()
+		default:
+			text.WriteByte(value[i])
+		}
+	}
+	return value
+}
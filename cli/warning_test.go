@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestWarnings(t *testing.T) {
+	tests := []struct {
+		name   string
+		header http.Header
+		want   []string
+	}{
+		{
+			"WarningsWithoutWarningHeaders",
+			http.Header{"Content-Type": []string{"application/json"}},
+			nil,
+		},
+		{
+			"WarningsWithDeprecationHeaders",
+			http.Header{"Warning": []string{
+				`299 Elasticsearch-6.0.0-8f0685b "Deprecated field [inline] used, expected [source] instead" "Mon, 01 Jan 2018 00:00:00 GMT"`,
+				`299 Elasticsearch-6.0.0-8f0685b "The [string] field is deprecated, use \"text\" instead"`,
+			}},
+			[]string{
+				"Deprecated field [inline] used, expected [source] instead",
+				`The [string] field is deprecated, use "text" instead`,
+			},
+		},
+		{
+			"WarningsWithUnquotedHeader",
+			http.Header{"Warning": []string{"299 - unquoted warning"}},
+			[]string{"299 - unquoted warning"},
+		},
+		{
+			"WarningsWithUnterminatedText",
+			http.Header{"Warning": []string{`299 - "unterminated`}},
+			[]string{`299 - "unterminated`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Warnings(&http.Response{Header: tt.header}); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Warnings() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
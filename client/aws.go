@@ -0,0 +1,106 @@
+package client
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// AWSCredentials are the credentials used to sign the requests with SigV4
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken is only set for temporary credentials
+	SessionToken string
+}
+
+// LoadAWSCredentials returns the credentials from the AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN environment variables or, when
+// unset, from the profile in the shared credentials file. The profile falls
+// back to AWS_PROFILE and then to "default", and the file to
+// AWS_SHARED_CREDENTIALS_FILE and then to ~/.aws/credentials.
+func LoadAWSCredentials(profile string) (AWSCredentials, error) {
+	var credentials = AWSCredentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	// An explicit profile takes precedence over the environment credentials
+	if profile == "" && credentials.AccessKeyID != "" && credentials.SecretAccessKey != "" {
+		return credentials, nil
+	}
+
+	if profile == "" {
+		profile = os.Getenv("AWS_PROFILE")
+	}
+	if profile == "" {
+		profile = "default"
+	}
+
+	var path = os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return AWSCredentials{}, errors.New("no AWS credentials found")
+		}
+		path = filepath.Join(home, ".aws", "credentials")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return AWSCredentials{}, fmt.Errorf("no AWS credentials found in the environment or \"%s\"", path)
+	}
+	defer f.Close()
+
+	return parseAWSCredentials(bufio.NewScanner(f), profile, path)
+}
+
+// parseAWSCredentials reads the profile credentials from the INI formatted
+// shared credentials file
+func parseAWSCredentials(scanner *bufio.Scanner, profile, path string) (AWSCredentials, error) {
+	var credentials AWSCredentials
+	var section string
+	var found bool
+	for scanner.Scan() {
+		var line = strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			found = found || section == profile
+			continue
+		}
+
+		var parts = strings.SplitN(line, "=", 2)
+		if section != profile || len(parts) != 2 {
+			continue
+		}
+
+		var value = strings.TrimSpace(parts[1])
+		switch strings.ToLower(strings.TrimSpace(parts[0])) {
+		case "aws_access_key_id":
+			credentials.AccessKeyID = value
+		case "aws_secret_access_key":
+			credentials.SecretAccessKey = value
+		case "aws_session_token":
+			credentials.SessionToken = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return AWSCredentials{}, err
+	}
+
+	if !found {
+		return AWSCredentials{}, fmt.Errorf("AWS profile \"%s\" not found in \"%s\"", profile, path)
+	}
+
+	if credentials.AccessKeyID == "" || credentials.SecretAccessKey == "" {
+		return AWSCredentials{}, fmt.Errorf("AWS profile \"%s\" has no access key id or secret access key", profile)
+	}
+	return credentials, nil
+}
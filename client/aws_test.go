@@ -0,0 +1,114 @@
+package client
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testAWSCredentialsFile = `[default]
+aws_access_key_id = AKIDDEFAULT
+aws_secret_access_key = defaultsecret
+
+# Temporary credentials
+[search]
+aws_access_key_id=AKIDSEARCH
+aws_secret_access_key=searchsecret
+aws_session_token=searchtoken
+
+[incomplete]
+aws_access_key_id = AKIDINCOMPLETE
+`
+
+func TestLoadAWSCredentials(t *testing.T) {
+	var dir, err = ioutil.TempDir("", "aws")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var path = filepath.Join(dir, "credentials")
+	if err := ioutil.WriteFile(path, []byte(testAWSCredentialsFile), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		profile string
+		want    AWSCredentials
+		wantErr string
+	}{
+		{
+			"LoadsTheEnvironmentCredentials",
+			map[string]string{"AWS_ACCESS_KEY_ID": "AKIDENV", "AWS_SECRET_ACCESS_KEY": "envsecret", "AWS_SESSION_TOKEN": "envtoken"},
+			"",
+			AWSCredentials{AccessKeyID: "AKIDENV", SecretAccessKey: "envsecret", SessionToken: "envtoken"},
+			"",
+		},
+		{
+			"LoadsTheDefaultProfile",
+			nil,
+			"",
+			AWSCredentials{AccessKeyID: "AKIDDEFAULT", SecretAccessKey: "defaultsecret"},
+			"",
+		},
+		{
+			"LoadsTheAWSProfileEnvironmentVariable",
+			map[string]string{"AWS_PROFILE": "search"},
+			"",
+			AWSCredentials{AccessKeyID: "AKIDSEARCH", SecretAccessKey: "searchsecret", SessionToken: "searchtoken"},
+			"",
+		},
+		{
+			"ProfileTakesPrecedenceOverTheEnvironment",
+			map[string]string{"AWS_ACCESS_KEY_ID": "AKIDENV", "AWS_SECRET_ACCESS_KEY": "envsecret"},
+			"search",
+			AWSCredentials{AccessKeyID: "AKIDSEARCH", SecretAccessKey: "searchsecret", SessionToken: "searchtoken"},
+			"",
+		},
+		{
+			"FailsWhenTheProfileDoesNotExist",
+			nil,
+			"missing",
+			AWSCredentials{},
+			`AWS profile "missing" not found in "` + path + `"`,
+		},
+		{
+			"FailsWhenTheProfileIsIncomplete",
+			nil,
+			"incomplete",
+			AWSCredentials{},
+			`AWS profile "incomplete" has no access key id or secret access key`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var env = map[string]string{
+				"AWS_ACCESS_KEY_ID":           "",
+				"AWS_SECRET_ACCESS_KEY":       "",
+				"AWS_SESSION_TOKEN":           "",
+				"AWS_PROFILE":                 "",
+				"AWS_SHARED_CREDENTIALS_FILE": path,
+			}
+			for k, v := range tt.env {
+				env[k] = v
+			}
+			for k, v := range env {
+				defer os.Setenv(k, os.Getenv(k))
+				os.Setenv(k, v)
+			}
+
+			got, err := LoadAWSCredentials(tt.profile)
+			if (err != nil && err.Error() != tt.wantErr) || (err == nil && tt.wantErr != "") {
+				t.Errorf("LoadAWSCredentials() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadAWSCredentials() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,18 +1,31 @@
 package client
 
 import (
-	"crypto/tls"
+	"context"
 	"io"
+	"io/ioutil"
 	"net/http"
-	"strings"
+	"net/url"
+	"time"
 
 	"github.com/marclop/elasticsearch-cli/utils"
 )
 
+type contextKey int
+
+const (
+	elapsedKey contextKey = iota
+	requestSizeKey
+	nodeKey
+)
+
 // HTTP Wraps an http.Client with its config
 type HTTP struct {
 	Config *Config
 	caller *http.Client
+	pool   hostPool
+	// sleep is used to override time.Sleep in the tests
+	sleep func(time.Duration)
 }
 
 // NewHTTP is the factory function for HTTP
@@ -24,14 +37,10 @@ func NewHTTP(config *Config, client *http.Client) *HTTP {
 		}
 	}
 
-	transport := http.DefaultTransport.(*http.Transport)
-	if transport.TLSClientConfig == nil {
-		transport.TLSClientConfig = &tls.Config{
-			InsecureSkipVerify: config.insecure,
-		}
-	} else {
-		transport.TLSClientConfig.InsecureSkipVerify = config.insecure
-	}
+	// The default transport is cloned so it's not modified globally
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config.tls
+	transport.Proxy = config.proxyURL
 
 	return &HTTP{
 		Config: config,
@@ -44,22 +53,151 @@ func NewHTTP(config *Config, client *http.Client) *HTTP {
 
 // TODO: Bulk operations
 
-// HandleCall is responsible to perform HTTP requests against the secified url
+// HandleCall is responsible to perform HTTP requests against the secified path
 // it relies on the underlying net/http.Client or Injected CallerInterface.
+// The path is sent verbatim, while the query parameters are encoded. The body
+// is streamed to the remote endpoint and can be nil.
+//
+// When multiple hosts are configured, the requests are round-robined across
+// them and failed over to the next host on connection errors, as long as the
+// body can be sent again (i.e. it's not streamed from a file or stdin).
 //
 // Because we have to inject the `Content-Type: application/json`, client.Do is used.
-func (c *HTTP) HandleCall(method, url, body string) (*http.Response, error) {
-	var bodyIoReader io.Reader
-	if body != "" {
-		bodyIoReader = strings.NewReader(body)
+func (c *HTTP) HandleCall(method, path string, query url.Values, body io.Reader) (*http.Response, error) {
+	var hosts = c.pool.order(c.Config.hostPorts())
+	req, err := c.createRequest(method, c.fullURL(hosts[0], path, query), body)
+	if err != nil {
+		return nil, err
 	}
 
-	req, err := c.createRequest(method, c.fullURL(url), bodyIoReader)
+	for retry := 0; ; retry++ {
+		res, err := c.failover(req, retry > 0, hosts, path, query)
+		if retry >= c.Config.Retry.Retries || !c.Config.Retry.retryable(req, res, err) {
+			return res, err
+		}
+
+		var delay = c.Config.Retry.delay(retry, res)
+		if res != nil {
+			io.Copy(ioutil.Discard, res.Body)
+			res.Body.Close()
+		}
+
+		c.wait(delay)
+		hosts = c.pool.order(c.Config.hostPorts())
+	}
+}
+
+// failover sends the request to the hosts in order until one of them doesn't
+// fail with a connection error. When sent is true the request has already
+// been sent, so it's copied before sending it again.
+func (c *HTTP) failover(req *http.Request, sent bool, hosts []*hostPort, path string, query url.Values) (*http.Response, error) {
+	for i, host := range hosts {
+		var next = req
+		if sent {
+			var err error
+			if next, err = retryRequest(req, c.fullURL(host, path, query)); err != nil {
+				return nil, err
+			}
+		}
+		sent = true
+
+		if c.Config.Signer != nil {
+			if err := c.Config.Signer.Sign(next); err != nil {
+				return nil, err
+			}
+		}
+
+		res, err := c.do(next)
+		if err == nil {
+			c.pool.markAlive(host)
+			if len(hosts) > 1 && res.Request != nil {
+				res.Request = res.Request.WithContext(
+					context.WithValue(res.Request.Context(), nodeKey, next.URL.Host),
+				)
+			}
+			return res, nil
+		}
+
+		c.pool.markDead(host)
+		if i == len(hosts)-1 || !canRetry(req) {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// wait sleeps for the specified duration before retrying a request
+func (c *HTTP) wait(d time.Duration) {
+	if c.sleep != nil {
+		c.sleep(d)
+		return
+	}
+	time.Sleep(d)
+}
+
+// do performs the request, storing the elapsed time in the response request
+// and decoding the gzip responses
+func (c *HTTP) do(req *http.Request) (*http.Response, error) {
+	var start = time.Now()
+	res, err := c.caller.Do(req)
 	if err != nil {
 		return nil, err
 	}
+	decompressResponse(res)
+
+	if res.Request != nil {
+		res.Request = res.Request.WithContext(
+			context.WithValue(res.Request.Context(), elapsedKey, time.Since(start)),
+		)
+	}
+
+	return res, nil
+}
+
+// canRetry returns true when the request body can be sent again
+func canRetry(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// retryRequest returns a copy of the request targeting the specified URL
+func retryRequest(req *http.Request, target string) (*http.Request, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+
+	var next = req.Clone(req.Context())
+	next.URL = u
+	next.Host = ""
+	if req.GetBody != nil {
+		if next.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	return next, nil
+}
+
+// Elapsed returns the time it took to obtain the response, measured from when
+// the request was sent until the response headers were received. False is
+// returned when the response wasn't obtained through HandleCall.
+func Elapsed(res *http.Response) (time.Duration, bool) {
+	if res == nil || res.Request == nil {
+		return 0, false
+	}
 
-	return c.caller.Do(req)
+	elapsed, ok := res.Request.Context().Value(elapsedKey).(time.Duration)
+	return elapsed, ok
+}
+
+// Node returns the host:port of the node which served the request, which is
+// only set when the requests are sent to multiple hosts
+func Node(res *http.Response) (string, bool) {
+	if res == nil || res.Request == nil {
+		return "", false
+	}
+
+	node, ok := res.Request.Context().Value(nodeKey).(string)
+	return node, ok
 }
 
 func (c *HTTP) createRequest(method string, url string, body io.Reader) (*http.Request, error) {
@@ -72,18 +210,30 @@ func (c *HTTP) createRequest(method string, url string, body io.Reader) (*http.R
 		req.Header.Add(key, value)
 	}
 
-	if (c.Config.User != "") && (c.Config.Pass != "") {
-		req.SetBasicAuth(c.Config.User, c.Config.Pass)
+	if authorization := c.Config.authorization(); authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	if c.Config.Compress {
+		return compressRequest(req)
 	}
 
 	return req, nil
 }
 
-func (c *HTTP) fullURL(url string) string {
-	return utils.ConcatStrings(c.Config.HTTPAdress(), url)
+func (c *HTTP) fullURL(host *hostPort, path string, query url.Values) string {
+	if len(query) == 0 {
+		return utils.ConcatStrings(host.address(), path)
+	}
+	return utils.ConcatStrings(host.address(), path, "?", query.Encode())
 }
 
-// SetHost modifies the target host
+// SetHost modifies the target host, forgetting the rest of the hosts
 func (c *HTTP) SetHost(value string) error {
-	return c.Config.SetHost(value)
+	if err := c.Config.SetHost(value); err != nil {
+		return err
+	}
+
+	c.pool.reset()
+	return nil
 }
@@ -1,10 +1,15 @@
 package client
 
 import (
+	"crypto/tls"
+	"errors"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,6 +17,7 @@ import (
 )
 
 func TestNewClient(t *testing.T) {
+	var tlsConfig = &tls.Config{ServerName: "elasticsearch"}
 	type args struct {
 		config *Config
 		client *http.Client
@@ -24,30 +30,51 @@ func TestNewClient(t *testing.T) {
 		{
 			"NewClientHasMockClientInjected",
 			args{
-				config: &Config{&hostPort{"http://localhost", 9200}, "", "", time.Duration(10), nil, false},
+				config: &Config{HostPort: &hostPort{Host: "http://localhost", Port: 9200}, Timeout: time.Duration(10)},
 				client: &http.Client{},
 			},
-			NewHTTP(
-				&Config{&hostPort{"http://localhost", 9200}, "", "", time.Duration(10), nil, false},
-				&http.Client{},
-			),
+			&HTTP{
+				Config: &Config{HostPort: &hostPort{Host: "http://localhost", Port: 9200}, Timeout: time.Duration(10)},
+				caller: &http.Client{},
+			},
 		},
 		{
 			"NewClientHaNoInjections",
 			args{
-				config: &Config{&hostPort{"http://localhost", 9200}, "", "", time.Duration(10), nil, false},
+				config: &Config{HostPort: &hostPort{Host: "http://localhost", Port: 9200}, Timeout: time.Duration(10), tls: tlsConfig},
 				client: nil,
 			},
-			NewHTTP(
-				&Config{&hostPort{"http://localhost", 9200}, "", "", time.Duration(10), nil, false},
-				nil,
-			),
+			&HTTP{
+				Config: &Config{HostPort: &hostPort{Host: "http://localhost", Port: 9200}, Timeout: time.Duration(10), tls: tlsConfig},
+				caller: &http.Client{Timeout: time.Duration(10)},
+			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := NewHTTP(tt.args.config, tt.args.client); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("NewClient() = %v, want %v", got, tt.want)
+			got := NewHTTP(tt.args.config, tt.args.client)
+			if !reflect.DeepEqual(got.Config, tt.want.Config) {
+				t.Errorf("NewClient() Config = %v, want %v", got.Config, tt.want.Config)
+			}
+			if got.caller.Timeout != tt.want.caller.Timeout {
+				t.Errorf("NewClient() Timeout = %v, want %v", got.caller.Timeout, tt.want.caller.Timeout)
+			}
+			if tt.args.client != nil {
+				if got.caller != tt.args.client {
+					t.Errorf("NewClient() caller = %v, want %v", got.caller, tt.args.client)
+				}
+				return
+			}
+
+			transport, ok := got.caller.Transport.(*http.Transport)
+			if !ok || transport == http.DefaultTransport {
+				t.Fatalf("NewClient() Transport = %v, want a copy of the default transport", got.caller.Transport)
+			}
+			if transport.TLSClientConfig != tt.args.config.tls {
+				t.Errorf("NewClient() TLSClientConfig = %v, want %v", transport.TLSClientConfig, tt.args.config.tls)
+			}
+			if http.DefaultTransport.(*http.Transport).TLSClientConfig == tt.args.config.tls {
+				t.Errorf("NewClient() modified the default transport")
 			}
 		})
 	}
@@ -60,8 +87,9 @@ func TestClient_HandleCall(t *testing.T) {
 	}
 	type args struct {
 		method string
-		url    string
-		body   string
+		path   string
+		query  url.Values
+		body   io.Reader
 	}
 	tests := []struct {
 		name    string
@@ -73,87 +101,92 @@ func TestClient_HandleCall(t *testing.T) {
 		{
 			"HandleCallHTTPByEmptyMockCaller",
 			fields{
-				&Config{&hostPort{"http://localhost", 9200}, "", "", time.Duration(10), nil, false},
+				&Config{HostPort: &hostPort{Host: "http://localhost", Port: 9200}, Timeout: time.Duration(10)},
 				NewMock(MockResponse{
-					Response: http.Response{},
+					Response: http.Response{Body: http.NoBody},
 				}),
 			},
 			args{
 				"GET",
 				"",
-				"",
+				nil,
+				nil,
 			},
-			&http.Response{},
+			&http.Response{Body: http.NoBody},
 			false,
 		},
 		{
 			"HandleCallHTTPSByEmptyMockCaller",
 			fields{
-				&Config{&hostPort{"https://localhost", 9200}, "", "", time.Duration(10), nil, false},
+				&Config{HostPort: &hostPort{Host: "https://localhost", Port: 9200}, Timeout: time.Duration(10)},
 				NewMock(MockResponse{
-					Response: http.Response{},
+					Response: http.Response{Body: http.NoBody},
 				}),
 			},
 			args{
 				"GET",
 				"",
-				"",
+				nil,
+				nil,
 			},
-			&http.Response{},
+			&http.Response{Body: http.NoBody},
 			false,
 		},
 		{
 			"HandleCallWithBodyByEmptyMockCaller",
 			fields{
-				&Config{&hostPort{"http://localhost", 9200}, "", "", time.Duration(10), nil, false},
+				&Config{HostPort: &hostPort{Host: "http://localhost", Port: 9200}, Timeout: time.Duration(10)},
 				NewMock(MockResponse{
-					Response: http.Response{},
+					Response: http.Response{Body: http.NoBody},
 				}),
 			},
 			args{
 				"GET",
 				"",
-				"{\"hello\":true}",
+				nil,
+				strings.NewReader("{\"hello\":true}"),
 			},
-			&http.Response{},
+			&http.Response{Body: http.NoBody},
 			false,
 		},
 		{
 			"HandleCallWithHeadersByEmptyMockCaller",
 			fields{
-				&Config{&hostPort{"http://localhost", 9200}, "", "", time.Duration(10), map[string]string{"Content-Type": "application/json"}, false},
+				&Config{HostPort: &hostPort{Host: "http://localhost", Port: 9200}, Timeout: time.Duration(10), headers: map[string]string{"Content-Type": "application/json"}},
 				NewMock(MockResponse{
-					Response: http.Response{},
+					Response: http.Response{Body: http.NoBody},
 				}),
 			},
 			args{
 				"GET",
 				"",
-				"",
+				nil,
+				nil,
 			},
-			&http.Response{},
+			&http.Response{Body: http.NoBody},
 			false,
 		},
 		{
 			"HandleCallWithAuthAndHeadersByEmptyMockCaller",
 			fields{
-				&Config{&hostPort{"http://localhost", 9200}, "marc", "marc", time.Duration(10), map[string]string{"Content-Type": "application/json"}, false},
+				&Config{HostPort: &hostPort{Host: "http://localhost", Port: 9200}, User: "marc", Pass: "marc", Timeout: time.Duration(10), headers: map[string]string{"Content-Type": "application/json"}},
 				NewMock(MockResponse{
-					Response: http.Response{},
+					Response: http.Response{Body: http.NoBody},
 				}),
 			},
 			args{
 				"GET",
 				"",
-				"",
+				nil,
+				nil,
 			},
-			&http.Response{},
+			&http.Response{Body: http.NoBody},
 			false,
 		},
 		{
 			"HandleCallWithInvalidMethodByEmptyMockCaller",
 			fields{
-				&Config{&hostPort{"http://localhost", 9200}, "", "", time.Duration(10), nil, false},
+				&Config{HostPort: &hostPort{Host: "http://localhost", Port: 9200}, Timeout: time.Duration(10)},
 				NewMock(MockResponse{
 					Response: http.Response{},
 				}),
@@ -161,7 +194,8 @@ func TestClient_HandleCall(t *testing.T) {
 			args{
 				"   ",
 				"",
-				"",
+				nil,
+				nil,
 			},
 			nil,
 			true,
@@ -173,7 +207,7 @@ func TestClient_HandleCall(t *testing.T) {
 				Config: tt.fields.config,
 				caller: tt.fields.caller,
 			}
-			got, err := c.HandleCall(tt.args.method, tt.args.url, tt.args.body)
+			got, err := c.HandleCall(tt.args.method, tt.args.path, tt.args.query, tt.args.body)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Client.HandleCall() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -205,7 +239,7 @@ func TestClient_createRequest(t *testing.T) {
 		{
 			"createRequestWithCorrectMethod",
 			fields{
-				&Config{&hostPort{"http://localhost", 9200}, "", "", time.Duration(10), nil, false},
+				&Config{HostPort: &hostPort{Host: "http://localhost", Port: 9200}, Timeout: time.Duration(10)},
 				&http.Client{},
 			},
 			args{
@@ -227,7 +261,7 @@ func TestClient_createRequest(t *testing.T) {
 		{
 			"createRequestWithIncorrectMethod",
 			fields{
-				&Config{&hostPort{"http://localhost", 9200}, "", "", time.Duration(10), nil, false},
+				&Config{HostPort: &hostPort{Host: "http://localhost", Port: 9200}, Timeout: time.Duration(10)},
 				&http.Client{},
 			},
 			args{
@@ -252,6 +286,7 @@ func TestClient_createRequest(t *testing.T) {
 			}
 			if tt.want != nil {
 				tt.want.URL, _ = url.Parse(tt.args.url)
+				tt.want = tt.want.WithContext(got.Context())
 			}
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("Client.createRequest() = %v, want %v", got, tt.want)
@@ -259,3 +294,230 @@ func TestClient_createRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_fullURL(t *testing.T) {
+	var localhost = &hostPort{Host: "http://localhost", Port: 9200}
+	type args struct {
+		path  string
+		query url.Values
+	}
+	tests := []struct {
+		name     string
+		hostPort *hostPort
+		args     args
+		want     string
+	}{
+		{
+			"fullURLWithoutQuery",
+			localhost,
+			args{"/myIndex/_doc/AbC", nil},
+			"http://localhost:9200/myIndex/_doc/AbC",
+		},
+		{
+			"fullURLKeepsEncodedPath",
+			localhost,
+			args{"/myindex/_doc/my%2Fid", nil},
+			"http://localhost:9200/myindex/_doc/my%2Fid",
+		},
+		{
+			"fullURLEncodesQuery",
+			localhost,
+			args{"/_search", url.Values{"q": []string{"Field:Value"}, "filter_path": []string{"hits.hits._id", "took"}}},
+			"http://localhost:9200/_search?filter_path=hits.hits._id&filter_path=took&q=Field%3AValue",
+		},
+		{
+			"fullURLAppendsPathToPrefix",
+			&hostPort{Host: "https://gw.example.com", Port: 443, Path: "/es"},
+			args{"/_cat/indices", url.Values{"v": []string{""}}},
+			"https://gw.example.com:443/es/_cat/indices?v=",
+		},
+		{
+			"fullURLWithIPv6",
+			&hostPort{Host: "http://[::1]", Port: 9200},
+			args{"/", nil},
+			"http://[::1]:9200/",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &HTTP{
+				Config: &Config{HostPort: tt.hostPort, Timeout: time.Duration(10)},
+			}
+			got := c.fullURL(tt.hostPort, tt.args.path, tt.args.query)
+			if got != tt.want {
+				t.Errorf("Client.fullURL() = %v, want %v", got, tt.want)
+			}
+			req, err := c.createRequest("GET", got, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if uri := fmt.Sprint(req.URL); uri != tt.want {
+				t.Errorf("Client.createRequest() URL = %v, want %v", uri, tt.want)
+			}
+		})
+	}
+}
+
+func TestElapsed(t *testing.T) {
+	tests := []struct {
+		name   string
+		caller *http.Client
+		wantOk bool
+	}{
+		{
+			"ElapsedIsSetWhenResponseHasRequest",
+			NewMock(MockResponse{
+				Response: http.Response{Body: http.NoBody, Request: &http.Request{}},
+			}),
+			true,
+		},
+		{
+			"ElapsedIsNotSetWhenResponseHasNoRequest",
+			NewMock(MockResponse{
+				Response: http.Response{Body: http.NoBody},
+			}),
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &HTTP{
+				Config: &Config{HostPort: &hostPort{Host: "http://localhost", Port: 9200}, Timeout: time.Duration(10)},
+				caller: tt.caller,
+			}
+			res, err := c.HandleCall("GET", "/", nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if elapsed, ok := Elapsed(res); ok != tt.wantOk || elapsed < 0 {
+				t.Errorf("Elapsed() = %v, %v, want ok %v", elapsed, ok, tt.wantOk)
+			}
+		})
+	}
+}
+
+// hostRecorder records the hosts the requests are sent to, failing the
+// requests sent to the failing hosts
+type hostRecorder struct {
+	failing map[string]bool
+	hosts   []string
+	bodies  []string
+}
+
+func (r *hostRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.hosts = append(r.hosts, req.URL.Host)
+	if req.Body != nil {
+		body, _ := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		r.bodies = append(r.bodies, string(body))
+	}
+
+	if r.failing[req.URL.Host] {
+		return nil, errors.New("connection refused")
+	}
+	return &http.Response{StatusCode: 200, Body: http.NoBody, Request: req}, nil
+}
+
+func TestClient_HandleCallFailover(t *testing.T) {
+	type args struct {
+		calls int
+		body  func() io.Reader
+	}
+	tests := []struct {
+		name       string
+		failing    map[string]bool
+		args       args
+		wantHosts  []string
+		wantBodies []string
+		wantErr    bool
+	}{
+		{
+			"HandleCallRoundRobinsTheHosts",
+			nil,
+			args{3, func() io.Reader { return nil }},
+			[]string{"node1:9200", "node2:9200", "node1:9200"},
+			nil,
+			false,
+		},
+		{
+			"HandleCallFailsOverToTheNextHost",
+			map[string]bool{"node1:9200": true},
+			args{2, func() io.Reader { return strings.NewReader(`{"query":{}}`) }},
+			[]string{"node1:9200", "node2:9200", "node2:9200"},
+			[]string{`{"query":{}}`, `{"query":{}}`, `{"query":{}}`},
+			false,
+		},
+		{
+			"HandleCallFailsWhenAllHostsFail",
+			map[string]bool{"node1:9200": true, "node2:9200": true},
+			args{1, func() io.Reader { return nil }},
+			[]string{"node1:9200", "node2:9200"},
+			nil,
+			true,
+		},
+		{
+			"HandleCallDoesNotFailOverStreamedBodies",
+			map[string]bool{"node1:9200": true},
+			args{1, func() io.Reader { return ioutil.NopCloser(strings.NewReader(`{"query":{}}`)) }},
+			[]string{"node1:9200"},
+			[]string{`{"query":{}}`},
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var recorder = &hostRecorder{failing: tt.failing}
+			c := &HTTP{
+				Config: &Config{
+					HostPort: &hostPort{Host: "http://node1", Port: 9200},
+					hosts:    []*hostPort{{Host: "http://node2", Port: 9200}},
+				},
+				caller: &http.Client{Transport: recorder},
+			}
+
+			var err error
+			for i := 0; i < tt.args.calls; i++ {
+				_, err = c.HandleCall("GET", "/", nil, tt.args.body())
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Client.HandleCall() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(recorder.hosts, tt.wantHosts) {
+				t.Errorf("Client.HandleCall() hosts = %v, want %v", recorder.hosts, tt.wantHosts)
+			}
+			if !reflect.DeepEqual(recorder.bodies, tt.wantBodies) {
+				t.Errorf("Client.HandleCall() bodies = %v, want %v", recorder.bodies, tt.wantBodies)
+			}
+		})
+	}
+}
+
+func TestNode(t *testing.T) {
+	tests := []struct {
+		name   string
+		hosts  []*hostPort
+		want   string
+		wantOk bool
+	}{
+		{"NodeIsNotSetWithASingleHost", nil, "", false},
+		{"NodeIsSetWithMultipleHosts", []*hostPort{{Host: "http://node2", Port: 9200}}, "node1:9200", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &HTTP{
+				Config: &Config{
+					HostPort: &hostPort{Host: "http://node1", Port: 9200},
+					hosts:    tt.hosts,
+				},
+				caller: &http.Client{Transport: &hostRecorder{}},
+			}
+			res, err := c.HandleCall("GET", "/", nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if node, ok := Node(res); node != tt.want || ok != tt.wantOk {
+				t.Errorf("Node() = %v, %v, want %v, %v", node, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
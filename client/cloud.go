@@ -0,0 +1,55 @@
+package client
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/marclop/elasticsearch-cli/utils"
+)
+
+const defaultCloudPort = 443
+
+// ParseCloudID decodes an Elastic Cloud ID ("<name>:<base64 encoded info>")
+// into the Elasticsearch https host and port. The decoded info has the form
+// "<domain>[:<port>]$<elasticsearch uuid>[$<kibana uuid>]".
+func ParseCloudID(cloudID string) (string, int, error) {
+	var invalid = fmt.Errorf("cloud-id \"%s\" is invalid", cloudID)
+
+	var encoded = cloudID
+	if i := strings.LastIndex(cloudID, ":"); i >= 0 {
+		encoded = cloudID[i+1:]
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", 0, invalid
+	}
+
+	var parts = strings.Split(string(decoded), "$")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", 0, invalid
+	}
+
+	var domain, port = parts[0], defaultCloudPort
+	if i := strings.LastIndex(domain, ":"); i >= 0 {
+		port, err = strconv.Atoi(domain[i+1:])
+		if err != nil {
+			return "", 0, invalid
+		}
+		domain = domain[:i]
+	}
+
+	return utils.ConcatStrings("https://", parts[1], ".", domain), port, nil
+}
+
+// ParseCloudAuth splits the "<user>:<pass>" Elastic Cloud credentials
+func ParseCloudAuth(cloudAuth string) (string, string, error) {
+	var i = strings.Index(cloudAuth, ":")
+	if i <= 0 {
+		return "", "", errors.New("cloud-auth must be specified as \"<user>:<pass>\"")
+	}
+	return cloudAuth[:i], cloudAuth[i+1:], nil
+}
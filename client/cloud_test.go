@@ -0,0 +1,119 @@
+package client
+
+import "testing"
+
+func TestParseCloudID(t *testing.T) {
+	tests := []struct {
+		name     string
+		cloudID  string
+		wantHost string
+		wantPort int
+		wantErr  bool
+	}{
+		{
+			"ParseCloudIDSucceeds",
+			"my-deployment:dXMtZWFzdC0xLmF3cy5mb3VuZC5pbyRjZWM2ZjI2MWE3NGJmMjRjZTMzYmI4ODExYjg0Mjk0ZiRjNmMyY2E2ZDA0MjI0OWFmMGNjN2Q3YTllOTYyNTc0Mw==",
+			"https://cec6f261a74bf24ce33bb8811b84294f.us-east-1.aws.found.io",
+			443,
+			false,
+		},
+		{
+			"ParseCloudIDSucceedsWithPort",
+			"production:ZXUtd2VzdC0xLmF3cy5mb3VuZC5pbzo5MjQzJDRmYTg4MjFlNzU2MzQwMzJiZWQxY2YyMjExMGUyZjk3JGtpYmFuYQ==",
+			"https://4fa8821e75634032bed1cf22110e2f97.eu-west-1.aws.found.io",
+			9243,
+			false,
+		},
+		{
+			"ParseCloudIDSucceedsWithoutName",
+			"dXMtZWFzdC0xLmF3cy5mb3VuZC5pbyRjZWM2ZjI2MWE3NGJmMjRjZTMzYmI4ODExYjg0Mjk0ZiRjNmMyY2E2ZDA0MjI0OWFmMGNjN2Q3YTllOTYyNTc0Mw==",
+			"https://cec6f261a74bf24ce33bb8811b84294f.us-east-1.aws.found.io",
+			443,
+			false,
+		},
+		{
+			"ParseCloudIDFailsWhenNotBase64",
+			"my-deployment:not base64",
+			"",
+			0,
+			true,
+		},
+		{
+			"ParseCloudIDFailsWithoutElasticsearchUUID",
+			"my-deployment:b25seWRvbWFpbg==",
+			"",
+			0,
+			true,
+		},
+		{
+			"ParseCloudIDFailsWithInvalidPort",
+			"my-deployment:ZG9tYWluOnBvcnQkdXVpZA==",
+			"",
+			0,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, port, err := ParseCloudID(tt.cloudID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseCloudID() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if host != tt.wantHost || port != tt.wantPort {
+				t.Errorf("ParseCloudID() = %v, %v, want %v, %v", host, port, tt.wantHost, tt.wantPort)
+			}
+		})
+	}
+}
+
+func TestParseCloudAuth(t *testing.T) {
+	tests := []struct {
+		name      string
+		cloudAuth string
+		wantUser  string
+		wantPass  string
+		wantErr   bool
+	}{
+		{
+			"ParseCloudAuthSucceeds",
+			"elastic:changeme",
+			"elastic",
+			"changeme",
+			false,
+		},
+		{
+			"ParseCloudAuthKeepsColonsInPassword",
+			"elastic:change:me",
+			"elastic",
+			"change:me",
+			false,
+		},
+		{
+			"ParseCloudAuthFailsWithoutPassword",
+			"elastic",
+			"",
+			"",
+			true,
+		},
+		{
+			"ParseCloudAuthFailsWithoutUser",
+			":changeme",
+			"",
+			"",
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, pass, err := ParseCloudAuth(tt.cloudAuth)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseCloudAuth() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if user != tt.wantUser || pass != tt.wantPass {
+				t.Errorf("ParseCloudAuth() = %v, %v, want %v, %v", user, pass, tt.wantUser, tt.wantPass)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// requestSize contains the uncompressed and compressed sizes of the request
+// body, which are updated atomically when the body is streamed
+type requestSize struct {
+	size       int64
+	compressed int64
+}
+
+// compressRequest gzips the request body and asks for gzip responses. Bodies
+// which can be read twice are compressed in memory, while streamed bodies are
+// compressed as they're sent.
+func compressRequest(req *http.Request) (*http.Request, error) {
+	req.Header.Set("Accept-Encoding", "gzip")
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+
+	var sizes = new(requestSize)
+	req.Header.Set("Content-Encoding", "gzip")
+	req = req.WithContext(context.WithValue(req.Context(), requestSizeKey, sizes))
+
+	if req.GetBody == nil {
+		req.Body, req.ContentLength = gzipStream(req.Body, sizes), -1
+		return req, nil
+	}
+
+	content, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	var compressed bytes.Buffer
+	var gz = gzip.NewWriter(&compressed)
+	if _, err := gz.Write(content); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+
+	var payload = compressed.Bytes()
+	sizes.size, sizes.compressed = int64(len(content)), int64(len(payload))
+	req.Body, req.ContentLength = ioutil.NopCloser(bytes.NewReader(payload)), int64(len(payload))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(payload)), nil
+	}
+	return req, nil
+}
+
+// gzipStream compresses the body through a pipe, counting the bytes
+func gzipStream(body io.ReadCloser, sizes *requestSize) io.ReadCloser {
+	var r, w = io.Pipe()
+	go func() {
+		defer body.Close()
+		var gz = gzip.NewWriter(&countingWriter{w: w, n: &sizes.compressed})
+		_, err := io.Copy(gz, &countingReader{r: body, n: &sizes.size})
+		if err == nil {
+			err = gz.Close()
+		}
+		w.CloseWithError(err)
+	}()
+	return r
+}
+
+// RequestSize returns the uncompressed and compressed sizes of the request
+// body. False is returned when the request body wasn't compressed.
+func RequestSize(res *http.Response) (int64, int64, bool) {
+	if res == nil || res.Request == nil {
+		return 0, 0, false
+	}
+
+	sizes, ok := res.Request.Context().Value(requestSizeKey).(*requestSize)
+	if !ok {
+		return 0, 0, false
+	}
+	return atomic.LoadInt64(&sizes.size), atomic.LoadInt64(&sizes.compressed), true
+}
+
+// decompressResponse decodes the gzip response bodies which weren't already
+// decoded by the transport, i.e. when a custom http.Client is used
+func decompressResponse(res *http.Response) {
+	if res.Uncompressed || !strings.EqualFold(res.Header.Get("Content-Encoding"), "gzip") {
+		return
+	}
+
+	res.Body = &gzipBody{body: res.Body, counter: &countingReader{r: res.Body, n: new(int64)}}
+	res.Header.Del("Content-Encoding")
+	res.Header.Del("Content-Length")
+	res.ContentLength = -1
+	res.Uncompressed = true
+}
+
+// gzipBody decodes the gzip response body, the decoder is created on the
+// first read so empty bodies (i.e. HEAD responses) are not an error
+type gzipBody struct {
+	body    io.ReadCloser
+	counter *countingReader
+	reader  *gzip.Reader
+	err     error
+}
+
+func (b *gzipBody) Read(p []byte) (int, error) {
+	if b.reader == nil && b.err == nil {
+		b.reader, b.err = gzip.NewReader(b.counter)
+	}
+	if b.err != nil {
+		return 0, b.err
+	}
+	return b.reader.Read(p)
+}
+
+func (b *gzipBody) Close() error {
+	return b.body.Close()
+}
+
+// CompressedSize returns the number of compressed bytes read so far
+func (b *gzipBody) CompressedSize() int64 {
+	return atomic.LoadInt64(b.counter.n)
+}
+
+// CompressedSize returns the compressed size of the response body, which
+// needs to be read before. False is returned when it wasn't compressed.
+func CompressedSize(res *http.Response) (int64, bool) {
+	if res == nil {
+		return 0, false
+	}
+
+	body, ok := res.Body.(interface{ CompressedSize() int64 })
+	if !ok {
+		return 0, false
+	}
+	return body.CompressedSize(), true
+}
+
+type countingReader struct {
+	r io.Reader
+	n *int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	atomic.AddInt64(c.n, int64(n))
+	return n, err
+}
+
+type countingWriter struct {
+	w io.Writer
+	n *int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	atomic.AddInt64(c.n, int64(n))
+	return n, err
+}
@@ -0,0 +1,158 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func gzipContent(t *testing.T, content string) []byte {
+	var buf bytes.Buffer
+	var gz = gzip.NewWriter(&buf)
+	if _, err := gz.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// gzipRecorder decodes the gzip request bodies and returns a gzip response,
+// like a custom transport which doesn't decode the responses would
+type gzipRecorder struct {
+	response []byte
+	encoding string
+	body     string
+}
+
+func (r *gzipRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.encoding = req.Header.Get("Content-Encoding")
+	if req.Body != nil {
+		gz, err := gzip.NewReader(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		body, err := ioutil.ReadAll(gz)
+		if err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		r.body = string(body)
+	}
+
+	return &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{"Content-Encoding": {"gzip"}},
+		Body:       ioutil.NopCloser(bytes.NewReader(r.response)),
+		Request:    req,
+	}, nil
+}
+
+func TestClient_HandleCallCompress(t *testing.T) {
+	var response = strings.Repeat(`{"took":1}`, 100)
+	tests := []struct {
+		name     string
+		body     io.Reader
+		wantBody string
+	}{
+		{
+			"CompressesBufferedBodies",
+			strings.NewReader(strings.Repeat(`{"index":{}}`, 100)),
+			strings.Repeat(`{"index":{}}`, 100),
+		},
+		{
+			"CompressesStreamedBodies",
+			ioutil.NopCloser(strings.NewReader(strings.Repeat(`{"index":{}}`, 100))),
+			strings.Repeat(`{"index":{}}`, 100),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var recorder = &gzipRecorder{response: gzipContent(t, response)}
+			c := &HTTP{
+				Config: &Config{
+					HostPort: &hostPort{Host: "http://localhost", Port: 9200},
+					Compress: true,
+				},
+				caller: &http.Client{Transport: recorder},
+			}
+
+			res, err := c.HandleCall("POST", "/_bulk", nil, tt.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if recorder.encoding != "gzip" || recorder.body != tt.wantBody {
+				t.Errorf("Client.HandleCall() request = %v %v, want gzip %v", recorder.encoding, recorder.body, tt.wantBody)
+			}
+
+			size, compressed, ok := RequestSize(res)
+			if !ok || size != int64(len(tt.wantBody)) || compressed == 0 || compressed >= size {
+				t.Errorf("RequestSize() = %v, %v, %v", size, compressed, ok)
+			}
+
+			body, err := ioutil.ReadAll(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(body) != response {
+				t.Errorf("Client.HandleCall() response = %v, want %v", string(body), response)
+			}
+			if res.Header.Get("Content-Encoding") != "" {
+				t.Errorf("Client.HandleCall() Content-Encoding = %v, want it removed", res.Header.Get("Content-Encoding"))
+			}
+
+			if got, ok := CompressedSize(res); !ok || got != int64(len(recorder.response)) {
+				t.Errorf("CompressedSize() = %v, %v, want %v", got, ok, len(recorder.response))
+			}
+		})
+	}
+}
+
+func Test_decompressResponse(t *testing.T) {
+	tests := []struct {
+		name           string
+		res            *http.Response
+		want           string
+		wantCompressed bool
+	}{
+		{
+			"KeepsUncompressedResponses",
+			&http.Response{Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader("{}"))},
+			"{}",
+			false,
+		},
+		{
+			"KeepsResponsesDecodedByTheTransport",
+			&http.Response{Header: http.Header{}, Uncompressed: true, Body: ioutil.NopCloser(strings.NewReader("{}"))},
+			"{}",
+			false,
+		},
+		{
+			"DecodesEmptyGzipResponses",
+			&http.Response{Header: http.Header{"Content-Encoding": {"gzip"}}, Body: http.NoBody},
+			"",
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decompressResponse(tt.res)
+			got, err := ioutil.ReadAll(tt.res.Body)
+			if err != nil && err != io.EOF {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("decompressResponse() = %v, want %v", string(got), tt.want)
+			}
+			if _, ok := CompressedSize(tt.res); ok != tt.wantCompressed {
+				t.Errorf("CompressedSize() = %v, want %v", ok, tt.wantCompressed)
+			}
+		})
+	}
+}
@@ -1,12 +1,15 @@
 package client
 
 import (
+	"crypto/tls"
+	"encoding/base64"
 	"fmt"
+	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/asaskevich/govalidator"
 	"github.com/marclop/elasticsearch-cli/utils"
 )
 
@@ -19,77 +22,181 @@ type Config struct {
 	HostPort *hostPort
 	User     string
 	Pass     string
-	Timeout  time.Duration
+	// APIKey is either the encoded API key or its "id:api_key" pair
+	APIKey string
+	// Token is a bearer or service account token
+	Token   string
+	Timeout time.Duration
+	// Retry controls how the failed requests are retried
+	Retry RetryConfig
+	// Signer signs every request attempt, i.e. with AWS SigV4
+	Signer Signer
+	// Compress gzips the request bodies and asks for gzip responses
+	Compress bool
 	headers  map[string]string
-	insecure bool
+	tls      *tls.Config
+	// proxy is used to connect to the hosts that don't match noProxy
+	proxy   *url.URL
+	noProxy []string
+	// hosts are the seed hosts besides HostPort, the requests are
+	// round-robined across all of them
+	hosts []*hostPort
 }
 
 type hostPort struct {
 	Host string
 	Port int
+	// Path is the prefix of the request paths, without the trailing slash
+	Path string
+}
+
+// defaultPorts are used when no port is specified
+var defaultPorts = map[string]int{
+	"http":  9200,
+	"https": 443,
 }
 
 // NewClientConfig handles the parameters that will be used in the HTTP Client
 // If a socket is passed as a URL (http://<host>:<port>), the complex URL will prevail
-// from the passed port
-func NewClientConfig(host string, port int, user string, pass string, timeout int, insecure bool) (*Config, error) {
+// from the passed port. When the port is 0, it's derived from the URL scheme.
+func NewClientConfig(host string, port int, user string, pass string, timeout int, tlsConfig TLSConfig) (*Config, error) {
 	hp, err := newHostPortString(host, port)
 	if err != nil {
 		return nil, err
 	}
 
+	tc, err := newTLSConfig(tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Config{
 		HostPort: hp,
 		User:     user,
 		Pass:     pass,
 		Timeout:  time.Duration(timeout) * time.Second,
-		headers:  defaultClientHeaders,
-		insecure: insecure,
+		headers:  copyHeaders(defaultClientHeaders),
+		tls:      tc,
 	}, nil
 }
 
-func validateSchema(host string) error {
-	if govalidator.IsURL(host) {
-		return nil
+// parseHost parses the host URL, which must use the http or https scheme
+func parseHost(host string) (*url.URL, error) {
+	u, err := url.Parse(host)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return nil, fmt.Errorf("host \"%s\" is invalid", host)
 	}
-	return fmt.Errorf("host \"%s\" is invalid", host)
+	return u, nil
 }
 
+// newHostPortString parses the host URL into a hostPort. The port in the URL
+// takes precedence over the passed port, and when neither is specified the
+// port is derived from the scheme. Any path in the URL is kept as the prefix
+// of all the request paths (i.e. when Elasticsearch is behind a proxy).
 func newHostPortString(host string, port int) (*hostPort, error) {
-	err := validateSchema(host)
+	u, err := parseHost(host)
 	if err != nil {
 		return nil, err
 	}
 
-	urlString := strings.Split(host, "/")[2]
-	defaultHostPort := &hostPort{strings.Join(strings.Split(host, ":")[0:2], ":"), port}
-	if strings.Contains(urlString, ":") {
-		urlStringPort := strings.Split(urlString, ":")[1]
-		intedPort, err := strconv.Atoi(urlStringPort)
+	var hostname = u.Hostname()
+	// IPv6 literals need to be enclosed in brackets
+	if strings.Contains(hostname, ":") {
+		hostname = utils.ConcatStrings("[", hostname, "]")
+	}
+
+	if urlPort := u.Port(); urlPort != "" {
+		port, err = strconv.Atoi(urlPort)
 		if err != nil {
-			return nil, fmt.Errorf("invalid port \"%s\"", urlStringPort)
+			return nil, fmt.Errorf("invalid port \"%s\"", urlPort)
+		}
+	}
+
+	if port == 0 {
+		port = defaultPorts[u.Scheme]
+	}
+
+	return &hostPort{
+		Host: utils.ConcatStrings(u.Scheme, "://", hostname),
+		Port: port,
+		Path: strings.TrimRight(u.EscapedPath(), "/"),
+	}, nil
+}
+
+// authorization returns the Authorization header value of the configured
+// credentials. An API key takes precedence over a token, which in turn takes
+// precedence over the basic authentication user and password.
+func (c *Config) authorization() string {
+	switch {
+	case c.APIKey != "":
+		var key = c.APIKey
+		if strings.Contains(key, ":") {
+			key = base64.StdEncoding.EncodeToString([]byte(key))
 		}
-		return &hostPort{defaultHostPort.Host, intedPort}, nil
+		return utils.ConcatStrings("ApiKey ", key)
+	case c.Token != "":
+		return utils.ConcatStrings("Bearer ", c.Token)
+	case c.User != "" && c.Pass != "":
+		var credentials = utils.ConcatStrings(c.User, ":", c.Pass)
+		return utils.ConcatStrings("Basic ", base64.StdEncoding.EncodeToString([]byte(credentials)))
 	}
-	return defaultHostPort, nil
+	return ""
 }
 
 // SetHeader that will be sent with the request
 func (c *Config) SetHeader(key string, value string) {
-	c.headers[key] = value
+	if c.headers == nil {
+		c.headers = make(map[string]string)
+	}
+	c.headers[http.CanonicalHeaderKey(key)] = value
+}
+
+// DelHeader removes the header from the requests
+func (c *Config) DelHeader(key string) {
+	delete(c.headers, http.CanonicalHeaderKey(key))
+}
+
+// copyHeaders returns a copy of the headers, so they aren't shared
+func copyHeaders(headers map[string]string) map[string]string {
+	var copied = make(map[string]string, len(headers))
+	for key, value := range headers {
+		copied[key] = value
+	}
+	return copied
 }
 
-// HTTPAdress returns the host and port combination so it can
-// be used by the Client http://host:port
+// HTTPAdress returns the host, port and path prefix combination so it can
+// be used by the Client http://host:port/prefix
 func (c *Config) HTTPAdress() string {
-	return utils.ConcatStrings(c.HostPort.Host, ":", strconv.Itoa(c.HostPort.Port))
+	return c.HostPort.address()
 }
 
-// SetHost modifies the target host
+// SetHost modifies the target host, removing any other seed hosts. When the
+// host doesn't specify a port, the default port of its scheme is used.
 func (c *Config) SetHost(value string) error {
-	hostPort, err := newHostPortString(value, c.HostPort.Port)
+	hostPort, err := newHostPortString(value, 0)
 	if err == nil {
 		c.HostPort = hostPort
+		c.hosts = nil
 	}
 	return err
 }
+
+// AddHost adds a seed host, using the port when the host doesn't specify one
+func (c *Config) AddHost(value string, port int) error {
+	hostPort, err := newHostPortString(value, port)
+	if err == nil {
+		c.hosts = append(c.hosts, hostPort)
+	}
+	return err
+}
+
+// hostPorts returns all of the seed hosts
+func (c *Config) hostPorts() []*hostPort {
+	return append([]*hostPort{c.HostPort}, c.hosts...)
+}
+
+// address returns the http://host:port/prefix combination
+func (h *hostPort) address() string {
+	return utils.ConcatStrings(h.Host, ":", strconv.Itoa(h.Port), h.Path)
+}
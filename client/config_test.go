@@ -1,6 +1,7 @@
 package client
 
 import (
+	"crypto/tls"
 	"reflect"
 	"testing"
 	"time"
@@ -8,12 +9,12 @@ import (
 
 func TestNewClientConfig(t *testing.T) {
 	type args struct {
-		host     string
-		port     int
-		user     string
-		pass     string
-		timeout  int
-		insecure bool
+		host    string
+		port    int
+		user    string
+		pass    string
+		timeout int
+		tls     TLSConfig
 	}
 	tests := []struct {
 		name    string
@@ -29,15 +30,13 @@ func TestNewClientConfig(t *testing.T) {
 				"",
 				"",
 				10,
-				false,
+				TLSConfig{},
 			},
 			&Config{
-				&hostPort{"http://localhost", 9200},
-				"",
-				"",
-				time.Duration(10 * time.Second),
-				defaultClientHeaders,
-				false,
+				HostPort: &hostPort{Host: "http://localhost", Port: 9200},
+				Timeout:  time.Duration(10 * time.Second),
+				headers:  defaultClientHeaders,
+				tls:      &tls.Config{},
 			},
 			false,
 		},
@@ -49,7 +48,7 @@ func TestNewClientConfig(t *testing.T) {
 				"",
 				"",
 				10,
-				false,
+				TLSConfig{},
 			},
 			nil,
 			true,
@@ -62,22 +61,51 @@ func TestNewClientConfig(t *testing.T) {
 				"",
 				"",
 				10,
-				false,
+				TLSConfig{},
 			},
 			&Config{
-				&hostPort{"http://localhost", 9201},
+				HostPort: &hostPort{Host: "http://localhost", Port: 9201},
+				Timeout:  time.Duration(10 * time.Second),
+				headers:  defaultClientHeaders,
+				tls:      &tls.Config{},
+			},
+			false,
+		},
+		{
+			"NewClientConfigSucceedsWithTLSSettings",
+			args{
+				"https://localhost",
+				9200,
 				"",
 				"",
-				time.Duration(10 * time.Second),
-				defaultClientHeaders,
-				false,
+				10,
+				TLSConfig{Insecure: true, ServerName: "elasticsearch"},
+			},
+			&Config{
+				HostPort: &hostPort{Host: "https://localhost", Port: 9200},
+				Timeout:  time.Duration(10 * time.Second),
+				headers:  defaultClientHeaders,
+				tls:      &tls.Config{InsecureSkipVerify: true, ServerName: "elasticsearch"},
 			},
 			false,
 		},
+		{
+			"NewClientConfigFailsDueMissingClientKey",
+			args{
+				"https://localhost",
+				9200,
+				"",
+				"",
+				10,
+				TLSConfig{ClientCert: "client.pem"},
+			},
+			nil,
+			true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := NewClientConfig(tt.args.host, tt.args.port, tt.args.user, tt.args.pass, tt.args.timeout, tt.args.insecure)
+			got, err := NewClientConfig(tt.args.host, tt.args.port, tt.args.user, tt.args.pass, tt.args.timeout, tt.args.tls)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("NewClientConfig() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -89,41 +117,105 @@ func TestNewClientConfig(t *testing.T) {
 	}
 }
 
-func Test_validateSchema(t *testing.T) {
+func Test_newHostPortString(t *testing.T) {
 	type args struct {
 		host string
+		port int
 	}
 	tests := []struct {
 		name    string
 		args    args
+		want    *hostPort
 		wantErr bool
 	}{
 		{
-			"ValidateSchemaSucceedsWithHTTP",
-			args{
-				"http://localhost",
-			},
+			"newHostPortStringUsesPassedPort",
+			args{"http://localhost", 9201},
+			&hostPort{Host: "http://localhost", Port: 9201},
 			false,
 		},
 		{
-			"ValidateSchemaSucceedsWithHTTPS",
-			args{
-				"https://localhost",
-			},
+			"newHostPortStringPrefersURLPort",
+			args{"http://localhost:9201", 9200},
+			&hostPort{Host: "http://localhost", Port: 9201},
 			false,
 		},
 		{
-			"ValidateSchemaFailsWhenSchemaIsInvalid",
-			args{
-				"wat://localhost",
-			},
+			"newHostPortStringDefaultsHTTPPort",
+			args{"http://localhost", 0},
+			&hostPort{Host: "http://localhost", Port: 9200},
+			false,
+		},
+		{
+			"newHostPortStringDefaultsHTTPSPort",
+			args{"https://es.example.com", 0},
+			&hostPort{Host: "https://es.example.com", Port: 443},
+			false,
+		},
+		{
+			"newHostPortStringRemovesTrailingSlash",
+			args{"http://localhost:9200/", 0},
+			&hostPort{Host: "http://localhost", Port: 9200},
+			false,
+		},
+		{
+			"newHostPortStringKeepsPathPrefix",
+			args{"https://gw.example.com/es/", 0},
+			&hostPort{Host: "https://gw.example.com", Port: 443, Path: "/es"},
+			false,
+		},
+		{
+			"newHostPortStringKeepsNestedPathPrefix",
+			args{"https://gw.example.com:8443/proxy/es", 0},
+			&hostPort{Host: "https://gw.example.com", Port: 8443, Path: "/proxy/es"},
+			false,
+		},
+		{
+			"newHostPortStringSucceedsWithIPv6",
+			args{"http://[::1]:9200", 0},
+			&hostPort{Host: "http://[::1]", Port: 9200},
+			false,
+		},
+		{
+			"newHostPortStringSucceedsWithIPv6WithoutPort",
+			args{"https://[fe80::1]/es", 0},
+			&hostPort{Host: "https://[fe80::1]", Port: 443, Path: "/es"},
+			false,
+		},
+		{
+			"newHostPortStringSucceedsWithIPv4",
+			args{"http://127.0.0.1", 9200},
+			&hostPort{Host: "http://127.0.0.1", Port: 9200},
+			false,
+		},
+		{
+			"newHostPortStringFailsWithInvalidPort",
+			args{"http://localhost:port", 9200},
+			nil,
+			true,
+		},
+		{
+			"newHostPortStringFailsWithoutScheme",
+			args{"localhost:9200", 0},
+			nil,
+			true,
+		},
+		{
+			"newHostPortStringFailsWithoutHost",
+			args{"http://", 0},
+			nil,
 			true,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := validateSchema(tt.args.host); (err != nil) != tt.wantErr {
-				t.Errorf("validateSchema() error = %v, wantErr %v", err, tt.wantErr)
+			got, err := newHostPortString(tt.args.host, tt.args.port)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("newHostPortString() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newHostPortString() = %v, want %v", got, tt.want)
 			}
 		})
 	}
@@ -146,8 +238,8 @@ func TestConfig_HTTPAdress(t *testing.T) {
 			"HTTPAdressIsValid",
 			fields{
 				&hostPort{
-					"http://localhost",
-					9200,
+					Host: "http://localhost",
+					Port: 9200,
 				},
 				"",
 				"",
@@ -160,8 +252,8 @@ func TestConfig_HTTPAdress(t *testing.T) {
 			"HTTPAdressIsValidWithNonDefaultPort",
 			fields{
 				&hostPort{
-					"http://localhost",
-					9230,
+					Host: "http://localhost",
+					Port: 9230,
 				},
 				"",
 				"",
@@ -202,14 +294,15 @@ func TestConfig_SetHost(t *testing.T) {
 		name    string
 		fields  fields
 		args    args
+		want    string
 		wantErr bool
 	}{
 		{
 			"SetHostSucceeds",
 			fields{
 				&hostPort{
-					"http://localhost",
-					9200,
+					Host: "http://localhost",
+					Port: 9200,
 				},
 				"",
 				"",
@@ -219,14 +312,33 @@ func TestConfig_SetHost(t *testing.T) {
 			args{
 				"https://localhost",
 			},
+			"https://localhost:443",
+			false,
+		},
+		{
+			"SetHostKeepsTheExplicitPort",
+			fields{
+				&hostPort{
+					Host: "https://localhost",
+					Port: 443,
+				},
+				"",
+				"",
+				time.Duration(10 * time.Second),
+				nil,
+			},
+			args{
+				"http://localhost:9201",
+			},
+			"http://localhost:9201",
 			false,
 		},
 		{
 			"SetHosFailsWhenHTTPSchemaIsInvalid",
 			fields{
 				&hostPort{
-					"http://localhost",
-					9200,
+					Host: "http://localhost",
+					Port: 9200,
 				},
 				"",
 				"",
@@ -236,6 +348,7 @@ func TestConfig_SetHost(t *testing.T) {
 			args{
 				"invalid://localhost",
 			},
+			"",
 			true,
 		},
 	}
@@ -251,9 +364,108 @@ func TestConfig_SetHost(t *testing.T) {
 			if err := c.SetHost(tt.args.value); (err != nil) != tt.wantErr {
 				t.Errorf("Config.SetHost() error = %v, wantErr %v", err, tt.wantErr)
 			}
-			if !tt.wantErr && c.HostPort.Host != tt.args.value {
-				t.Errorf("Config.SetHost() = %s, want %s", c.HostPort.Host, tt.args.value)
+			if !tt.wantErr && c.HTTPAdress() != tt.want {
+				t.Errorf("Config.SetHost() = %s, want %s", c.HTTPAdress(), tt.want)
 			}
 		})
 	}
 }
+
+func TestConfig_authorization(t *testing.T) {
+	type fields struct {
+		user   string
+		pass   string
+		apiKey string
+		token  string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			"authorizationIsEmptyWithoutCredentials",
+			fields{},
+			"",
+		},
+		{
+			"authorizationIsEmptyWithoutPassword",
+			fields{user: "elastic"},
+			"",
+		},
+		{
+			"authorizationWithBasicAuth",
+			fields{user: "elastic", pass: "changeme"},
+			"Basic ZWxhc3RpYzpjaGFuZ2VtZQ==",
+		},
+		{
+			"authorizationWithEncodedAPIKey",
+			fields{apiKey: "VnVhQ2ZHY0JDZGJrUW0tZTVhT3g6dWkybHAyYXhUTm1zeWFrdzl0dk5udw=="},
+			"ApiKey VnVhQ2ZHY0JDZGJrUW0tZTVhT3g6dWkybHAyYXhUTm1zeWFrdzl0dk5udw==",
+		},
+		{
+			"authorizationWithAPIKeyPair",
+			fields{apiKey: "VuaCfGcBCdbkQm-e5aOx:ui2lp2axTNmsyakw9tvNnw"},
+			"ApiKey VnVhQ2ZHY0JDZGJrUW0tZTVhT3g6dWkybHAyYXhUTm1zeWFrdzl0dk5udw==",
+		},
+		{
+			"authorizationWithToken",
+			fields{token: "dGhpcyBpcyBub3QgYSByZWFsIHRva2Vu"},
+			"Bearer dGhpcyBpcyBub3QgYSByZWFsIHRva2Vu",
+		},
+		{
+			"authorizationAPIKeyTakesPrecedence",
+			fields{user: "elastic", pass: "changeme", apiKey: "id:key", token: "token"},
+			"ApiKey aWQ6a2V5",
+		},
+		{
+			"authorizationTokenTakesPrecedenceOverBasicAuth",
+			fields{user: "elastic", pass: "changeme", token: "token"},
+			"Bearer token",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{
+				User:   tt.fields.user,
+				Pass:   tt.fields.pass,
+				APIKey: tt.fields.apiKey,
+				Token:  tt.fields.token,
+			}
+			if got := c.authorization(); got != tt.want {
+				t.Errorf("Config.authorization() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_SetHeader(t *testing.T) {
+	first, err := NewClientConfig("http://localhost", 9200, "", "", 10, TLSConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := NewClientConfig("http://localhost", 9200, "", "", 10, TLSConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first.SetHeader("x-opaque-id", "first")
+	first.SetHeader("content-type", "application/x-ndjson")
+
+	var want = map[string]string{"Content-Type": "application/x-ndjson", "X-Opaque-Id": "first"}
+	if !reflect.DeepEqual(first.headers, want) {
+		t.Errorf("Config.SetHeader() = %v, want %v", first.headers, want)
+	}
+
+	if !reflect.DeepEqual(second.headers, defaultClientHeaders) {
+		t.Errorf("Config.SetHeader() leaked the headers = %v, want %v", second.headers, defaultClientHeaders)
+	}
+	if !reflect.DeepEqual(defaultClientHeaders, map[string]string{"Content-Type": "application/json"}) {
+		t.Errorf("Config.SetHeader() modified the default headers = %v", defaultClientHeaders)
+	}
+
+	first.DelHeader("X-OPAQUE-ID")
+	if _, ok := first.headers["X-Opaque-Id"]; ok {
+		t.Errorf("Config.DelHeader() = %v, want X-Opaque-Id removed", first.headers)
+	}
+}
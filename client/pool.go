@@ -0,0 +1,131 @@
+package client
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// deadTimeout is the time a host is considered dead after failing once,
+	// it's doubled on every consecutive failure up to maxDeadTimeout
+	deadTimeout    = 60 * time.Second
+	maxDeadTimeout = 30 * time.Minute
+)
+
+// hostPool round-robins the requests across the hosts and keeps track of the
+// hosts that failed, which are skipped until their dead timeout expires. Its
+// zero value is ready to use.
+type hostPool struct {
+	mu   sync.Mutex
+	next int
+	dead map[string]*deadHost
+	// sniffed contains the hosts discovered through the nodes info API
+	sniffed []*hostPort
+	// now is used to override the current time in the tests
+	now func() time.Time
+}
+
+type deadHost struct {
+	failures int
+	until    time.Time
+}
+
+// order returns the hosts in the order they need to be tried: the alive hosts
+// starting from the next one in the rotation followed by the dead ones, which
+// are sorted by the time they're resurrected.
+func (p *hostPool) order(seeds []*hostPort) []*hostPort {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var hosts = uniqueHosts(append(seeds, p.sniffed...))
+	var start = p.next % len(hosts)
+	p.next++
+
+	var alive, dead []*hostPort
+	for i := range hosts {
+		var host = hosts[(start+i)%len(hosts)]
+		if d, ok := p.dead[host.address()]; ok && p.currentTime().Before(d.until) {
+			dead = append(dead, host)
+			continue
+		}
+		alive = append(alive, host)
+	}
+
+	sort.SliceStable(dead, func(i, j int) bool {
+		return p.dead[dead[i].address()].until.Before(p.dead[dead[j].address()].until)
+	})
+
+	return append(alive, dead...)
+}
+
+// markDead marks the host as dead, doubling its dead timeout when it had
+// already failed before
+func (p *hostPool) markDead(host *hostPort) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.dead == nil {
+		p.dead = make(map[string]*deadHost)
+	}
+
+	var d, ok = p.dead[host.address()]
+	if !ok {
+		d = new(deadHost)
+		p.dead[host.address()] = d
+	}
+	d.failures++
+
+	var timeout = deadTimeout << uint(d.failures-1)
+	if timeout > maxDeadTimeout || timeout <= 0 {
+		timeout = maxDeadTimeout
+	}
+	d.until = p.currentTime().Add(timeout)
+}
+
+// markAlive resets the failures of the host
+func (p *hostPool) markAlive(host *hostPort) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.dead, host.address())
+}
+
+// setSniffed replaces the hosts discovered through the nodes info API
+func (p *hostPool) setSniffed(hosts []*hostPort) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.sniffed = hosts
+}
+
+// reset forgets the sniffed and dead hosts
+func (p *hostPool) reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.sniffed = nil
+	p.dead = nil
+	p.next = 0
+}
+
+func (p *hostPool) currentTime() time.Time {
+	if p.now != nil {
+		return p.now()
+	}
+	return time.Now()
+}
+
+// uniqueHosts removes the duplicated hosts, keeping the first occurrence
+func uniqueHosts(hosts []*hostPort) []*hostPort {
+	var seen = make(map[string]bool, len(hosts))
+	var unique = make([]*hostPort, 0, len(hosts))
+	for _, host := range hosts {
+		if seen[host.address()] {
+			continue
+		}
+		seen[host.address()] = true
+		unique = append(unique, host)
+	}
+	return unique
+}
@@ -0,0 +1,141 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHostPool(t *testing.T) {
+	var node1 = &hostPort{Host: "http://node1", Port: 9200}
+	var node2 = &hostPort{Host: "http://node2", Port: 9200}
+	var node3 = &hostPort{Host: "http://node3", Port: 9200}
+	var seeds = []*hostPort{node1, node2, node3}
+	var now = time.Date(2017, 9, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		setup func(p *hostPool)
+		want  [][]*hostPort
+	}{
+		{
+			"orderRoundRobinsTheHosts",
+			func(p *hostPool) {},
+			[][]*hostPort{
+				{node1, node2, node3},
+				{node2, node3, node1},
+				{node3, node1, node2},
+				{node1, node2, node3},
+			},
+		},
+		{
+			"orderTriesTheDeadHostsLast",
+			func(p *hostPool) { p.markDead(node1) },
+			[][]*hostPort{
+				{node2, node3, node1},
+				{node2, node3, node1},
+				{node3, node2, node1},
+			},
+		},
+		{
+			"orderSortsTheDeadHostsByResurrection",
+			func(p *hostPool) {
+				p.markDead(node2)
+				p.markDead(node2)
+				p.markDead(node1)
+			},
+			[][]*hostPort{
+				{node3, node1, node2},
+			},
+		},
+		{
+			"orderResurrectsTheHostsAfterTheDeadTimeout",
+			func(p *hostPool) {
+				p.markDead(node1)
+				p.now = func() time.Time { return now.Add(deadTimeout) }
+			},
+			[][]*hostPort{
+				{node1, node2, node3},
+			},
+		},
+		{
+			"orderKeepsTheHostsDeadUntilTheIncreasedTimeout",
+			func(p *hostPool) {
+				p.markDead(node1)
+				p.markDead(node1)
+				p.now = func() time.Time { return now.Add(deadTimeout) }
+			},
+			[][]*hostPort{
+				{node2, node3, node1},
+			},
+		},
+		{
+			"orderIncludesTheAliveHostsAgain",
+			func(p *hostPool) {
+				p.markDead(node1)
+				p.markAlive(node1)
+			},
+			[][]*hostPort{
+				{node1, node2, node3},
+			},
+		},
+		{
+			"orderIncludesTheSniffedHostsWithoutDuplicates",
+			func(p *hostPool) {
+				p.setSniffed([]*hostPort{{Host: "http://node1", Port: 9200}, {Host: "http://node4", Port: 9200}})
+			},
+			[][]*hostPort{
+				{node1, node2, node3, {Host: "http://node4", Port: 9200}},
+			},
+		},
+		{
+			"orderForgetsTheHostsWhenReset",
+			func(p *hostPool) {
+				p.setSniffed([]*hostPort{{Host: "http://node4", Port: 9200}})
+				p.markDead(node1)
+				p.reset()
+			},
+			[][]*hostPort{
+				{node1, node2, node3},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p = &hostPool{now: func() time.Time { return now }}
+			tt.setup(p)
+			for i, want := range tt.want {
+				if got := p.order(seeds); !reflect.DeepEqual(got, want) {
+					t.Errorf("hostPool.order() call %d = %v, want %v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestHostPool_markDead(t *testing.T) {
+	var host = &hostPort{Host: "http://node1", Port: 9200}
+	var now = time.Date(2017, 9, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		failures int
+		want     time.Duration
+	}{
+		{"markDeadOnce", 1, deadTimeout},
+		{"markDeadDoublesTheTimeout", 2, 2 * deadTimeout},
+		{"markDeadQuadruplesTheTimeout", 3, 4 * deadTimeout},
+		{"markDeadCapsTheTimeout", 10, maxDeadTimeout},
+		{"markDeadCapsTheTimeoutWithoutOverflowing", 100, maxDeadTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p = &hostPool{now: func() time.Time { return now }}
+			for i := 0; i < tt.failures; i++ {
+				p.markDead(host)
+			}
+			if got := p.dead[host.address()].until.Sub(now); got != tt.want {
+				t.Errorf("hostPool.markDead() timeout = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
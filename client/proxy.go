@@ -0,0 +1,103 @@
+package client
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// proxySchemes are the supported proxy URL schemes
+var proxySchemes = map[string]bool{
+	"http":    true,
+	"https":   true,
+	"socks5":  true,
+	"socks5h": true,
+}
+
+// SetProxy sets the http, https or socks5 proxy URL, which can contain the
+// proxy credentials, used to connect to the hosts that don't match any of the
+// noProxy entries. The entries are host names, which also match their
+// subdomains, IP addresses or CIDR ranges, optionally followed by a port.
+// When no proxy is set, the proxy environment variables are used.
+func (c *Config) SetProxy(proxy string, noProxy []string) error {
+	u, err := url.Parse(proxy)
+	if err != nil || !proxySchemes[u.Scheme] || u.Host == "" {
+		return fmt.Errorf("proxy \"%s\" is invalid, must be an http, https or socks5 URL", redactURL(proxy))
+	}
+
+	c.proxy = u
+	c.noProxy = nil
+	for _, entries := range noProxy {
+		for _, entry := range strings.Split(entries, ",") {
+			if entry = strings.ToLower(strings.TrimSpace(entry)); entry != "" {
+				c.noProxy = append(c.noProxy, entry)
+			}
+		}
+	}
+	return nil
+}
+
+// proxyURL returns the proxy used for the request, nil when the request is
+// sent directly
+func (c *Config) proxyURL(req *http.Request) (*url.URL, error) {
+	if c.proxy == nil {
+		return http.ProxyFromEnvironment(req)
+	}
+
+	if c.bypassProxy(req.URL) {
+		return nil, nil
+	}
+	return c.proxy, nil
+}
+
+// bypassProxy returns true when the URL matches any of the noProxy entries
+func (c *Config) bypassProxy(u *url.URL) bool {
+	var host, port = strings.ToLower(u.Hostname()), u.Port()
+	for _, entry := range c.noProxy {
+		if entry == "*" {
+			return true
+		}
+
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if ip := net.ParseIP(host); ip != nil && network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+
+		var entryHost, entryPort = entry, ""
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			entryHost, entryPort = h, p
+		}
+		entryHost = strings.Trim(entryHost, "[]")
+		if entryPort != "" && entryPort != port {
+			continue
+		}
+
+		entryHost = strings.TrimPrefix(entryHost, ".")
+		if host == entryHost || strings.HasSuffix(host, "."+entryHost) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactURL returns the URL with its password redacted
+func redactURL(value string) string {
+	u, err := url.Parse(value)
+	if err == nil {
+		return u.Redacted()
+	}
+
+	// The credentials can't be told apart when the URL is invalid
+	if i := strings.LastIndex(value, "@"); i >= 0 {
+		var scheme string
+		if j := strings.Index(value, "://"); j >= 0 && j < i {
+			scheme = value[:j+3]
+		}
+		return scheme + "xxxxx" + value[i:]
+	}
+	return value
+}
@@ -0,0 +1,102 @@
+package client
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// retryStatusCodes are the transient HTTP status codes which are retried
+var retryStatusCodes = map[int]bool{
+	http.StatusTooManyRequests:    true,
+	http.StatusBadGateway:         true,
+	http.StatusServiceUnavailable: true,
+	http.StatusGatewayTimeout:     true,
+}
+
+// idempotentMethods are retried unless RetryConfig.NonIdempotent is set
+var idempotentMethods = map[string]bool{
+	"GET":     true,
+	"HEAD":    true,
+	"PUT":     true,
+	"DELETE":  true,
+	"OPTIONS": true,
+}
+
+// RetryConfig controls how the requests which fail due to connection errors
+// or transient status codes (429, 502, 503 and 504) are retried
+type RetryConfig struct {
+	// Retries is the maximum number of retries, 0 disables them
+	Retries int
+	// Backoff is the delay before the first retry, which is doubled on every
+	// retry with a random jitter of up to half of the delay
+	Backoff time.Duration
+	// MaxBackoff caps the delay between retries, including the delay set by
+	// the Retry-After response header. There's no cap when it's 0.
+	MaxBackoff time.Duration
+	// NonIdempotent enables retrying non idempotent requests (i.e. POST)
+	NonIdempotent bool
+}
+
+// retryable returns true when the failed request can be sent again
+func (r RetryConfig) retryable(req *http.Request, res *http.Response, err error) bool {
+	if !canRetry(req) || (!idempotentMethods[req.Method] && !r.NonIdempotent) {
+		return false
+	}
+	return err != nil || retryStatusCodes[res.StatusCode]
+}
+
+// delay returns the time to wait before the retry, which is the response's
+// Retry-After when set or the exponential backoff with jitter otherwise
+func (r RetryConfig) delay(retry int, res *http.Response) time.Duration {
+	if after, ok := retryAfter(res); ok {
+		return r.capped(after)
+	}
+
+	if r.Backoff <= 0 {
+		return 0
+	}
+
+	var backoff = r.Backoff
+	for i := 0; i < retry && (r.MaxBackoff == 0 || backoff < r.MaxBackoff); i++ {
+		backoff *= 2
+	}
+	backoff = r.capped(backoff)
+
+	var half = int64(backoff / 2)
+	return time.Duration(half + rand.Int63n(half+1))
+}
+
+func (r RetryConfig) capped(d time.Duration) time.Duration {
+	if r.MaxBackoff > 0 && d > r.MaxBackoff {
+		return r.MaxBackoff
+	}
+	return d
+}
+
+// retryAfter parses the Retry-After response header, which is either the
+// number of seconds to wait or an HTTP date
+func retryAfter(res *http.Response) (time.Duration, bool) {
+	if res == nil {
+		return 0, false
+	}
+
+	var value = res.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if d := time.Until(date); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,200 @@
+package client
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient_HandleCallRetries(t *testing.T) {
+	var unavailable = MockResponse{Response: http.Response{StatusCode: 503, Body: http.NoBody}}
+	var ok = MockResponse{Response: http.Response{StatusCode: 200, Body: http.NoBody}}
+	var refused = MockResponse{Error: errors.New("connection refused")}
+	type args struct {
+		method string
+		body   io.Reader
+	}
+	tests := []struct {
+		name       string
+		retry      RetryConfig
+		args       args
+		responses  []MockResponse
+		wantStatus int
+		wantDelays []time.Duration
+		wantErr    bool
+	}{
+		{
+			"HandleCallDoesNotRetryWhenDisabled",
+			RetryConfig{},
+			args{"GET", nil},
+			[]MockResponse{unavailable},
+			503,
+			nil,
+			false,
+		},
+		{
+			"HandleCallRetriesTransientStatusCodes",
+			RetryConfig{Retries: 3},
+			args{"GET", nil},
+			[]MockResponse{
+				{Response: http.Response{StatusCode: 429, Body: http.NoBody}},
+				{Response: http.Response{StatusCode: 502, Body: http.NoBody}},
+				{Response: http.Response{StatusCode: 504, Body: http.NoBody}},
+				ok,
+			},
+			200,
+			[]time.Duration{0, 0, 0},
+			false,
+		},
+		{
+			"HandleCallRetriesConnectionErrors",
+			RetryConfig{Retries: 2},
+			args{"HEAD", nil},
+			[]MockResponse{refused, ok},
+			200,
+			[]time.Duration{0},
+			false,
+		},
+		{
+			"HandleCallReturnsTheLastResponseWhenRetriesAreExhausted",
+			RetryConfig{Retries: 2},
+			args{"DELETE", nil},
+			[]MockResponse{unavailable, unavailable, unavailable},
+			503,
+			[]time.Duration{0, 0},
+			false,
+		},
+		{
+			"HandleCallReturnsTheLastErrorWhenRetriesAreExhausted",
+			RetryConfig{Retries: 1},
+			args{"GET", nil},
+			[]MockResponse{refused, refused},
+			0,
+			[]time.Duration{0},
+			true,
+		},
+		{
+			"HandleCallDoesNotRetryOtherStatusCodes",
+			RetryConfig{Retries: 3},
+			args{"GET", nil},
+			[]MockResponse{{Response: http.Response{StatusCode: 500, Body: http.NoBody}}},
+			500,
+			nil,
+			false,
+		},
+		{
+			"HandleCallDoesNotRetryNonIdempotentMethods",
+			RetryConfig{Retries: 3},
+			args{"POST", strings.NewReader(`{"query":{}}`)},
+			[]MockResponse{unavailable},
+			503,
+			nil,
+			false,
+		},
+		{
+			"HandleCallRetriesNonIdempotentMethodsWhenEnabled",
+			RetryConfig{Retries: 3, NonIdempotent: true},
+			args{"POST", strings.NewReader(`{"query":{}}`)},
+			[]MockResponse{unavailable, ok},
+			200,
+			[]time.Duration{0},
+			false,
+		},
+		{
+			"HandleCallDoesNotRetryStreamedBodies",
+			RetryConfig{Retries: 3},
+			args{"PUT", NewStringBody(`{"settings":{}}`)},
+			[]MockResponse{unavailable},
+			503,
+			nil,
+			false,
+		},
+		{
+			"HandleCallHonoursRetryAfter",
+			RetryConfig{Retries: 3, Backoff: time.Second, MaxBackoff: 10 * time.Second},
+			args{"GET", nil},
+			[]MockResponse{
+				{Response: http.Response{StatusCode: 429, Header: http.Header{"Retry-After": []string{"5"}}, Body: http.NoBody}},
+				{Response: http.Response{StatusCode: 503, Header: http.Header{"Retry-After": []string{"120"}}, Body: http.NoBody}},
+				ok,
+			},
+			200,
+			[]time.Duration{5 * time.Second, 10 * time.Second},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var delays []time.Duration
+			c := &HTTP{
+				Config: &Config{
+					HostPort: &hostPort{Host: "http://localhost", Port: 9200},
+					Retry:    tt.retry,
+				},
+				caller: NewMock(tt.responses...),
+				sleep:  func(d time.Duration) { delays = append(delays, d) },
+			}
+
+			res, err := c.HandleCall(tt.args.method, "/", nil, tt.args.body)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Client.HandleCall() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res != nil && res.StatusCode != tt.wantStatus {
+				t.Errorf("Client.HandleCall() status = %v, want %v", res.StatusCode, tt.wantStatus)
+			}
+			if !reflect.DeepEqual(delays, tt.wantDelays) {
+				t.Errorf("Client.HandleCall() delays = %v, want %v", delays, tt.wantDelays)
+			}
+		})
+	}
+}
+
+func TestRetryConfig_delay(t *testing.T) {
+	var retry = RetryConfig{Backoff: time.Second, MaxBackoff: 5 * time.Second}
+	tests := []struct {
+		name    string
+		retry   int
+		wantMin time.Duration
+		wantMax time.Duration
+	}{
+		{"delayOfTheFirstRetry", 0, 500 * time.Millisecond, time.Second},
+		{"delayIsDoubled", 1, time.Second, 2 * time.Second},
+		{"delayIsDoubledAgain", 2, 2 * time.Second, 4 * time.Second},
+		{"delayIsCapped", 10, 2500 * time.Millisecond, 5 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				if got := retry.delay(tt.retry, nil); got < tt.wantMin || got > tt.wantMax {
+					t.Fatalf("RetryConfig.delay() = %v, want between %v and %v", got, tt.wantMin, tt.wantMax)
+				}
+			}
+		})
+	}
+}
+
+func Test_retryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+		wantOk bool
+	}{
+		{"retryAfterWithoutHeader", nil, 0, false},
+		{"retryAfterWithSeconds", http.Header{"Retry-After": []string{"30"}}, 30 * time.Second, true},
+		{"retryAfterWithPastDate", http.Header{"Retry-After": []string{"Wed, 21 Oct 2015 07:28:00 GMT"}}, 0, true},
+		{"retryAfterWithInvalidValue", http.Header{"Retry-After": []string{"soon"}}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := retryAfter(&http.Response{Header: tt.header})
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("retryAfter() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
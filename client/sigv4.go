@@ -0,0 +1,243 @@
+package client
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/marclop/elasticsearch-cli/utils"
+)
+
+const (
+	sigV4Algorithm  = "AWS4-HMAC-SHA256"
+	sigV4TimeFormat = "20060102T150405Z"
+	sigV4DateFormat = "20060102"
+)
+
+// sigV4IgnoredHeaders aren't signed, since they're either modified by the
+// transport and proxies or they're the signature itself
+var sigV4IgnoredHeaders = map[string]bool{
+	"authorization":   true,
+	"user-agent":      true,
+	"x-amzn-trace-id": true,
+	"expect":          true,
+	"connection":      true,
+}
+
+// Signer signs the requests before they're sent to the remote endpoint, which
+// happens on every attempt, including the retries
+type Signer interface {
+	Sign(req *http.Request) error
+}
+
+// SigV4Signer signs the requests with the AWS Signature Version 4, which is
+// used by the Amazon Elasticsearch and OpenSearch services
+type SigV4Signer struct {
+	Credentials AWSCredentials
+	Region      string
+	Service     string
+
+	// now is used to override the current time in the tests
+	now func() time.Time
+}
+
+// NewSigV4Signer returns a SigV4Signer for the region and service (es when
+// empty), loading the credentials of the AWS profile.
+func NewSigV4Signer(region, service, profile string) (*SigV4Signer, error) {
+	if region == "" {
+		return nil, errors.New("region is required to sign the requests with AWS SigV4")
+	}
+
+	if service == "" {
+		service = "es"
+	}
+
+	credentials, err := LoadAWSCredentials(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SigV4Signer{Credentials: credentials, Region: region, Service: service}, nil
+}
+
+// Sign adds the X-Amz-Date, X-Amz-Security-Token (when using temporary
+// credentials) and Authorization headers to the request. To hash the payload,
+// bodies which can't be read twice are read into memory.
+func (s *SigV4Signer) Sign(req *http.Request) error {
+	payload, err := payloadHash(req)
+	if err != nil {
+		return err
+	}
+
+	var now = time.Now
+	if s.now != nil {
+		now = s.now
+	}
+	var t = now().UTC()
+
+	req.Header.Set("X-Amz-Date", t.Format(sigV4TimeFormat))
+	if s.Credentials.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.Credentials.SessionToken)
+	}
+
+	var scope = strings.Join([]string{t.Format(sigV4DateFormat), s.Region, s.Service, "aws4_request"}, "/")
+	canonicalHeaders, signedHeaders := canonicalHeaders(req)
+	var canonicalRequest = strings.Join([]string{
+		req.Method,
+		canonicalPath(req.URL),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		payload,
+	}, "\n")
+
+	var stringToSign = strings.Join([]string{
+		sigV4Algorithm,
+		t.Format(sigV4TimeFormat),
+		scope,
+		hashHex([]byte(canonicalRequest)),
+	}, "\n")
+
+	var key = hmacSHA256([]byte("AWS4"+s.Credentials.SecretAccessKey), t.Format(sigV4DateFormat))
+	for _, part := range []string{s.Region, s.Service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+
+	req.Header.Set("Authorization", utils.ConcatStrings(
+		sigV4Algorithm, " Credential=", s.Credentials.AccessKeyID, "/", scope,
+		", SignedHeaders=", signedHeaders,
+		", Signature=", hex.EncodeToString(hmacSHA256(key, stringToSign)),
+	))
+	return nil
+}
+
+// payloadHash returns the hex encoded SHA256 hash of the request body
+func payloadHash(req *http.Request) (string, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return hashHex(nil), nil
+	}
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return "", err
+		}
+		defer body.Close()
+
+		content, err := ioutil.ReadAll(body)
+		if err != nil {
+			return "", err
+		}
+		return hashHex(content), nil
+	}
+
+	content, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return "", err
+	}
+
+	req.Body = ioutil.NopCloser(bytes.NewReader(content))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(content)), nil
+	}
+	return hashHex(content), nil
+}
+
+// canonicalPath returns the URI encoded path. Except for S3, the already
+// encoded path is encoded once more.
+func canonicalPath(u *url.URL) string {
+	var path = u.EscapedPath()
+	if path == "" {
+		return "/"
+	}
+	return uriEncode(path, false)
+}
+
+// canonicalQuery returns the query parameters sorted by name and value
+func canonicalQuery(query url.Values) string {
+	var params = make([]string, 0, len(query))
+	for name, values := range query {
+		for _, value := range values {
+			params = append(params, utils.ConcatStrings(uriEncode(name, true), "=", uriEncode(value, true)))
+		}
+	}
+	sort.Strings(params)
+	return strings.Join(params, "&")
+}
+
+// canonicalHeaders returns the canonical headers, including the host, and the
+// list of signed headers
+func canonicalHeaders(req *http.Request) (string, string) {
+	var headers = map[string]string{"host": req.URL.Host}
+	if req.Host != "" {
+		headers["host"] = req.Host
+	}
+
+	for name, values := range req.Header {
+		var lower = strings.ToLower(name)
+		if sigV4IgnoredHeaders[lower] || lower == "host" {
+			continue
+		}
+
+		var trimmed = make([]string, 0, len(values))
+		for _, value := range values {
+			trimmed = append(trimmed, strings.Join(strings.Fields(value), " "))
+		}
+		headers[lower] = strings.Join(trimmed, ",")
+	}
+
+	var names = make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonical bytes.Buffer
+	for _, name := range names {
+		canonical.WriteString(utils.ConcatStrings(name, ":", headers[name], "\n"))
+	}
+	return canonical.String(), strings.Join(names, ";")
+}
+
+// uriEncode encodes all the characters except the unreserved ones (RFC 3986),
+// the slashes are only encoded when encodeSlash is set
+func uriEncode(value string, encodeSlash bool) string {
+	const hexChars = "0123456789ABCDEF"
+	var encoded strings.Builder
+	for i := 0; i < len(value); i++ {
+		var c = value[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			encoded.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			encoded.WriteByte(c)
+		default:
+			encoded.WriteByte('%')
+			encoded.WriteByte(hexChars[c>>4])
+			encoded.WriteByte(hexChars[c&15])
+		}
+	}
+	return encoded.String()
+}
+
+func hashHex(content []byte) string {
+	var sum = sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, content string) []byte {
+	var mac = hmac.New(sha256.New, key)
+	mac.Write([]byte(content))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,176 @@
+package client
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The expected signatures are the AWS Signature Version 4 test suite vectors
+var testAWSCredentials = AWSCredentials{
+	AccessKeyID:     "AKIDEXAMPLE",
+	SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+}
+
+func testSigV4Time() time.Time {
+	return time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+}
+
+func TestSigV4Signer_Sign(t *testing.T) {
+	type args struct {
+		method  string
+		url     string
+		headers map[string]string
+		body    string
+	}
+	tests := []struct {
+		name    string
+		service string
+		args    args
+		want    string
+	}{
+		{
+			"SignsGetVanilla",
+			"service",
+			args{"GET", "https://example.amazonaws.com/", nil, ""},
+			"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			"SignsTheQueryStringSortedByKey",
+			"service",
+			args{"GET", "https://example.amazonaws.com/?Param2=value2&Param1=value1", nil, ""},
+			"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+		{
+			"SignsTheHeadersAndQueryString",
+			"iam",
+			args{
+				"GET",
+				"https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08",
+				map[string]string{
+					"Content-Type": "application/x-www-form-urlencoded; charset=utf-8",
+					"User-Agent":   "elasticsearch-cli",
+				},
+				"",
+			},
+			"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7",
+		},
+		{
+			"SignsPostXWWWFormURLEncoded",
+			"service",
+			args{
+				"POST",
+				"https://example.amazonaws.com/",
+				map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+				"Param1=value1",
+			},
+			"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature=ff11897932ad3f4e8b18135d722051e5ac45fc38421b1da7b9d196a0fe09473a",
+		},
+		// Not part of the AWS test suite, the reserved characters in the query
+		// keys and values are encoded as %XX, and + is never a space
+		{
+			"SignsTheQueryStringWithReservedCharacters",
+			"service",
+			args{
+				"GET",
+				"https://example.amazonaws.com/?q=a%20b%2Fc%3Ad%26e&filter_path=hits.hits._id%2C%2A.took&k%3Dey=%21%2A%27%28%29%2B%24%40",
+				nil,
+				"",
+			},
+			"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=9ff808d24e92602168ca7343a8d80430bdae75f168dfee377a6c661dfcf46ae1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s = &SigV4Signer{
+				Credentials: testAWSCredentials,
+				Region:      "us-east-1",
+				Service:     tt.service,
+				now:         testSigV4Time,
+			}
+			req, _ := http.NewRequest(tt.args.method, tt.args.url, strings.NewReader(tt.args.body))
+			for k, v := range tt.args.headers {
+				req.Header.Set(k, v)
+			}
+
+			if err := s.Sign(req); err != nil {
+				t.Fatalf("SigV4Signer.Sign() error = %v", err)
+			}
+
+			if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+				t.Errorf("SigV4Signer.Sign() X-Amz-Date = %v, want %v", got, "20150830T123600Z")
+			}
+			if got := req.Header.Get("Authorization"); got != tt.want {
+				t.Errorf("SigV4Signer.Sign() Authorization = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSigV4Signer_SignBody(t *testing.T) {
+	var s = &SigV4Signer{
+		Credentials: AWSCredentials{
+			AccessKeyID:     testAWSCredentials.AccessKeyID,
+			SecretAccessKey: testAWSCredentials.SecretAccessKey,
+			SessionToken:    "session",
+		},
+		Region:  "eu-west-1",
+		Service: "es",
+		now:     testSigV4Time,
+	}
+	var body = `{"query":{"match_all":{}}}`
+
+	buffered, _ := http.NewRequest("POST", "https://search.example.com:443/my%2Findex/_search?q=a+b", strings.NewReader(body))
+	// A streamed body (i.e. stdin) which can't be read twice
+	streamed, _ := http.NewRequest("POST", "https://search.example.com:443/my%2Findex/_search?q=a+b", ioutil.NopCloser(strings.NewReader(body)))
+
+	for _, req := range []*http.Request{buffered, streamed} {
+		if err := s.Sign(req); err != nil {
+			t.Fatalf("SigV4Signer.Sign() error = %v", err)
+		}
+	}
+
+	if buffered.Header.Get("Authorization") != streamed.Header.Get("Authorization") {
+		t.Errorf("SigV4Signer.Sign() streamed = %v, want %v", streamed.Header.Get("Authorization"), buffered.Header.Get("Authorization"))
+	}
+
+	if !strings.Contains(buffered.Header.Get("Authorization"), "SignedHeaders=host;x-amz-date;x-amz-security-token,") {
+		t.Errorf("SigV4Signer.Sign() Authorization = %v, want the session token signed", buffered.Header.Get("Authorization"))
+	}
+
+	if got := streamed.Header.Get("X-Amz-Security-Token"); got != "session" {
+		t.Errorf("SigV4Signer.Sign() X-Amz-Security-Token = %v, want %v", got, "session")
+	}
+
+	if streamed.GetBody == nil {
+		t.Fatal("SigV4Signer.Sign() the streamed body can't be replayed")
+	}
+	for _, req := range []*http.Request{buffered, streamed} {
+		got, _ := ioutil.ReadAll(req.Body)
+		if string(got) != body {
+			t.Errorf("SigV4Signer.Sign() body = %v, want %v", string(got), body)
+		}
+	}
+}
+
+func Test_canonicalPath(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{"EmptyPathIsSlash", "https://example.com", "/"},
+		{"PathIsKept", "https://example.com/index/_doc/1", "/index/_doc/1"},
+		{"EncodedPathIsEncodedTwice", "https://example.com/index/_doc/a%2Fb%20c", "/index/_doc/a%252Fb%2520c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", tt.url, nil)
+			if got := canonicalPath(req.URL); got != tt.want {
+				t.Errorf("canonicalPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
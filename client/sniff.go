@@ -0,0 +1,76 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/marclop/elasticsearch-cli/utils"
+)
+
+// nodesInfo is the subset of the nodes info API response used for sniffing
+type nodesInfo struct {
+	Nodes map[string]struct {
+		HTTP struct {
+			PublishAddress string `json:"publish_address"`
+		} `json:"http"`
+	} `json:"nodes"`
+}
+
+// Sniff discovers the rest of the cluster nodes through the nodes info API,
+// adding their HTTP publish addresses to the hosts the requests are sent to.
+// The scheme of the configured host is used for the discovered nodes.
+func (c *HTTP) Sniff() error {
+	res, err := c.HandleCall("GET", "/_nodes/http", nil, nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("failed to sniff the cluster nodes: %s", res.Status)
+	}
+
+	var info nodesInfo
+	if err := json.NewDecoder(res.Body).Decode(&info); err != nil {
+		return fmt.Errorf("failed to sniff the cluster nodes: %s", err)
+	}
+
+	var scheme = strings.SplitN(c.Config.HostPort.Host, "://", 2)[0]
+	var hosts = make([]*hostPort, 0, len(info.Nodes))
+	for _, node := range info.Nodes {
+		if node.HTTP.PublishAddress == "" {
+			continue
+		}
+
+		host, err := parsePublishAddress(scheme, node.HTTP.PublishAddress)
+		if err != nil {
+			return fmt.Errorf("failed to sniff the cluster nodes: %s", err)
+		}
+		hosts = append(hosts, host)
+	}
+
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].address() < hosts[j].address() })
+	c.pool.setSniffed(hosts)
+	return nil
+}
+
+// parsePublishAddress parses the node's HTTP publish address, which is either
+// "<ip>:<port>" or "<hostname>/<ip>:<port>", in which case the hostname is used.
+func parsePublishAddress(scheme, address string) (*hostPort, error) {
+	if i := strings.Index(address, "/"); i >= 0 {
+		var hostname, ipPort = address[:i], address[i+1:]
+		address = ipPort
+		if hostname != "" {
+			_, port, err := net.SplitHostPort(ipPort)
+			if err != nil {
+				return nil, err
+			}
+			address = net.JoinHostPort(hostname, port)
+		}
+	}
+
+	return newHostPortString(utils.ConcatStrings(scheme, "://", address), 0)
+}
@@ -0,0 +1,125 @@
+package client
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestClient_Sniff(t *testing.T) {
+	var nodes = `{
+  "nodes": {
+    "b": {"http": {"publish_address": "es-data-2/10.0.0.2:9200"}},
+    "a": {"http": {"publish_address": "10.0.0.1:9201"}},
+    "c": {"name": "no-http"}
+  }
+}`
+	tests := []struct {
+		name     string
+		host     *hostPort
+		response MockResponse
+		want     []*hostPort
+		wantErr  bool
+	}{
+		{
+			"SniffSucceeds",
+			&hostPort{Host: "https://es.example.com", Port: 443},
+			MockResponse{Response: http.Response{StatusCode: 200, Body: NewStringBody(nodes)}},
+			[]*hostPort{
+				{Host: "https://10.0.0.1", Port: 9201},
+				{Host: "https://es-data-2", Port: 9200},
+			},
+			false,
+		},
+		{
+			"SniffFailsWithErrorResponse",
+			&hostPort{Host: "http://localhost", Port: 9200},
+			MockResponse{Response: http.Response{StatusCode: 401, Status: "401 Unauthorized", Body: http.NoBody}},
+			nil,
+			true,
+		},
+		{
+			"SniffFailsWithInvalidResponse",
+			&hostPort{Host: "http://localhost", Port: 9200},
+			MockResponse{Response: http.Response{StatusCode: 200, Body: NewStringBody("not json")}},
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &HTTP{
+				Config: &Config{HostPort: tt.host},
+				caller: NewMock(tt.response),
+			}
+			if err := c.Sniff(); (err != nil) != tt.wantErr {
+				t.Errorf("Client.Sniff() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(c.pool.sniffed, tt.want) {
+				t.Errorf("Client.Sniff() = %v, want %v", c.pool.sniffed, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parsePublishAddress(t *testing.T) {
+	type args struct {
+		scheme  string
+		address string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *hostPort
+		wantErr bool
+	}{
+		{
+			"parsePublishAddressWithIP",
+			args{"http", "10.0.0.1:9200"},
+			&hostPort{Host: "http://10.0.0.1", Port: 9200},
+			false,
+		},
+		{
+			"parsePublishAddressWithHostname",
+			args{"https", "es-data-1/10.0.0.1:9243"},
+			&hostPort{Host: "https://es-data-1", Port: 9243},
+			false,
+		},
+		{
+			"parsePublishAddressWithEmptyHostname",
+			args{"http", "/10.0.0.1:9200"},
+			&hostPort{Host: "http://10.0.0.1", Port: 9200},
+			false,
+		},
+		{
+			"parsePublishAddressWithIPv6",
+			args{"http", "[::1]:9200"},
+			&hostPort{Host: "http://[::1]", Port: 9200},
+			false,
+		},
+		{
+			"parsePublishAddressWithHostnameAndIPv6",
+			args{"http", "localhost/[::1]:9200"},
+			&hostPort{Host: "http://localhost", Port: 9200},
+			false,
+		},
+		{
+			"parsePublishAddressFailsWithoutPort",
+			args{"http", "es-data-1/10.0.0.1"},
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePublishAddress(tt.args.scheme, tt.args.address)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parsePublishAddress() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parsePublishAddress() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+)
+
+// TLSConfig contains the settings used to establish TLS connections with the
+// remote endpoint
+type TLSConfig struct {
+	// Insecure skips the server certificate verification
+	Insecure bool
+	// CACert is the path to a PEM encoded CA bundle used to verify the server
+	// certificate instead of the system's CA bundle
+	CACert string
+	// ClientCert and ClientKey are the paths to the PEM encoded certificate
+	// and key used for mutual TLS
+	ClientCert string
+	ClientKey  string
+	// ServerName overrides the name used to verify the server certificate
+	ServerName string
+}
+
+// newTLSConfig builds the tls.Config from the TLSConfig, loading the CA bundle
+// and the client certificate
+func newTLSConfig(c TLSConfig) (*tls.Config, error) {
+	var config = &tls.Config{
+		InsecureSkipVerify: c.Insecure,
+		ServerName:         c.ServerName,
+	}
+
+	if c.CACert != "" {
+		pem, err := ioutil.ReadFile(c.CACert)
+		if err != nil {
+			return nil, err
+		}
+
+		var pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("ca-cert \"%s\" doesn't contain any PEM encoded certificates", c.CACert)
+		}
+		config.RootCAs = pool
+	}
+
+	if (c.ClientCert == "") != (c.ClientKey == "") {
+		return nil, errors.New("client-cert and client-key must be specified together")
+	}
+
+	if c.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(c.ClientCert, c.ClientKey)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
@@ -0,0 +1,143 @@
+package client
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeCertificate writes a self-signed certificate and its key to dir,
+// returning the paths of both files
+func writeCertificate(t *testing.T, dir, name string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var certPath = filepath.Join(dir, name+".pem")
+	var keyPath = filepath.Join(dir, name+"-key.pem")
+	if err := ioutil.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certPath, keyPath
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "elasticsearch-cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca, _ := writeCertificate(t, dir, "ca")
+	cert, key := writeCertificate(t, dir, "client")
+	var invalid = filepath.Join(dir, "invalid.pem")
+	if err := ioutil.WriteFile(invalid, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		config       TLSConfig
+		wantRootCAs  bool
+		wantCertsLen int
+		wantErr      bool
+	}{
+		{
+			"NewTLSConfigSucceedsWithoutSettings",
+			TLSConfig{},
+			false,
+			0,
+			false,
+		},
+		{
+			"NewTLSConfigSucceedsWithCACert",
+			TLSConfig{CACert: ca},
+			true,
+			0,
+			false,
+		},
+		{
+			"NewTLSConfigSucceedsWithClientCertificate",
+			TLSConfig{CACert: ca, ClientCert: cert, ClientKey: key},
+			true,
+			1,
+			false,
+		},
+		{
+			"NewTLSConfigFailsWhenCACertDoesNotExist",
+			TLSConfig{CACert: filepath.Join(dir, "missing.pem")},
+			false,
+			0,
+			true,
+		},
+		{
+			"NewTLSConfigFailsWhenCACertIsNotPEM",
+			TLSConfig{CACert: invalid},
+			false,
+			0,
+			true,
+		},
+		{
+			"NewTLSConfigFailsWhenClientKeyIsMissing",
+			TLSConfig{ClientCert: cert},
+			false,
+			0,
+			true,
+		},
+		{
+			"NewTLSConfigFailsWhenClientKeyDoesNotMatch",
+			TLSConfig{ClientCert: cert, ClientKey: invalid},
+			false,
+			0,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newTLSConfig(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("newTLSConfig() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if (got.RootCAs != nil) != tt.wantRootCAs {
+				t.Errorf("newTLSConfig() RootCAs = %v, want %v", got.RootCAs, tt.wantRootCAs)
+			}
+			if len(got.Certificates) != tt.wantCertsLen {
+				t.Errorf("newTLSConfig() Certificates = %d, want %d", len(got.Certificates), tt.wantCertsLen)
+			}
+		})
+	}
+}
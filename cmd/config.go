@@ -0,0 +1,329 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/marclop/elasticsearch-cli/app"
+	"github.com/marclop/elasticsearch-cli/cli"
+	"github.com/marclop/elasticsearch-cli/credentials"
+	"github.com/marclop/elasticsearch-cli/profile"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
+)
+
+const redacted = "[REDACTED]"
+
+// secretSettings are redacted by "config show"
+var secretSettings = []string{"pass", "api-key", "token", "cloud-auth"}
+
+var (
+	configCmd = &cobra.Command{
+		Use:   "config",
+		Short: "Manages the cluster config files in $HOME/.elasticsearch-cli",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	configListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "Lists the configured clusters, marking the default one with *",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profiles, err := clusterProfiles()
+			if err != nil {
+				return err
+			}
+
+			names, err := profiles.List()
+			if err != nil {
+				return err
+			}
+
+			for _, name := range names {
+				var marker = " "
+				if name == profiles.Default() {
+					marker = "*"
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), marker, name)
+			}
+			return nil
+		},
+	}
+
+	configShowCmd = &cobra.Command{
+		Use:   "show [cluster]",
+		Short: "Shows the effective configuration of the cluster (flags > env > file), with the secrets redacted",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := selectCluster(args); err != nil {
+				return err
+			}
+			initConfig()
+
+			var settings = viper.AllSettings()
+			settings["cluster"] = clusterName()
+			// viper doesn't support string array flags
+			delete(settings, "header")
+			if len(headerFlags) > 0 {
+				settings["header"] = headerFlags
+			}
+			redactSettings(settings)
+
+			content, err := yaml.Marshal(settings)
+			if err != nil {
+				return err
+			}
+			_, err = cmd.OutOrStdout().Write(content)
+			return err
+		},
+	}
+
+	configAddCmd = &cobra.Command{
+		Use:   "add <cluster>",
+		Short: "Creates the cluster config file with the settings passed as flags, i.e. config add prod --host https://prod:9243 --user marc. --pass is saved in the credential store.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profiles, err := clusterProfiles()
+			if err != nil {
+				return err
+			}
+
+			var c app.Config
+			if err := viper.Unmarshal(&c); err != nil {
+				return err
+			}
+			c.Header = headerFlags
+			if err := c.Validate(); err != nil {
+				return err
+			}
+
+			settings, err := changedSettings(cmd.InheritedFlags())
+			if err != nil {
+				return err
+			}
+
+			// The password is kept out of the config file
+			pass, storePass := settings["pass"].(string)
+			delete(settings, "pass")
+
+			path, err := profiles.Add(args[0], settings)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "Created cluster config file:", path)
+
+			if !storePass {
+				return nil
+			}
+
+			store, err := credentialStore()
+			if err != nil {
+				return err
+			}
+			if err := store.Set(args[0], pass); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Stored the password of cluster \"%s\"\n", args[0])
+			return nil
+		},
+	}
+
+	configRemoveCmd = &cobra.Command{
+		Use:   "remove <cluster>",
+		Short: "Removes the cluster config file and its stored password",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profiles, err := clusterProfiles()
+			if err != nil {
+				return err
+			}
+
+			if err := profiles.Remove(args[0]); err != nil {
+				return err
+			}
+
+			store, err := credentialStore()
+			if err != nil {
+				return err
+			}
+			if err := store.Delete(args[0]); err != nil && err != credentials.ErrNotFound {
+				return err
+			}
+			return nil
+		},
+	}
+
+	configUseCmd = &cobra.Command{
+		Use:   "use <cluster>",
+		Short: "Sets the cluster used when --cluster isn't specified",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profiles, err := clusterProfiles()
+			if err != nil {
+				return err
+			}
+			return profiles.SetDefault(args[0])
+		},
+	}
+
+	configTestCmd = &cobra.Command{
+		Use:   "test [cluster]",
+		Short: "Tests the connectivity and credentials of the cluster calling / and _cluster/health",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := selectCluster(args); err != nil {
+				return err
+			}
+
+			esCli, err := newApplication()
+			if err != nil {
+				return err
+			}
+			return esCli.Check(cmd.OutOrStdout())
+		},
+	}
+)
+
+// configDir returns the directory of the cluster config files
+func configDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".elasticsearch-cli"), nil
+}
+
+// clusterProfiles returns the cluster config files in the config directory
+func clusterProfiles() (*profile.Profiles, error) {
+	dir, err := configDir()
+	if err != nil {
+		return nil, err
+	}
+	return profile.New(dir), nil
+}
+
+// clusterName returns the --cluster (or ES_CLUSTER) config name, defaulting
+// to the cluster set with "config use"
+func clusterName() string {
+	if cluster := viper.GetString("cluster"); cluster != "" {
+		return cluster
+	}
+
+	profiles, err := clusterProfiles()
+	if err != nil {
+		return profile.DefaultCluster
+	}
+	return profiles.Default()
+}
+
+// selectCluster uses the cluster passed as an argument
+func selectCluster(args []string) error {
+	if len(args) == 0 {
+		return nil
+	}
+
+	if err := checkCluster(args[0]); err != nil {
+		return err
+	}
+	viper.Set("cluster", args[0])
+	return nil
+}
+
+// checkCluster returns an error when the cluster doesn't have a config file,
+// unless it's the default one
+func checkCluster(name string) error {
+	profiles, err := clusterProfiles()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := profiles.Path(name); !ok && name != profile.DefaultCluster {
+		return fmt.Errorf("cluster \"%s\" doesn't exist", name)
+	}
+	return nil
+}
+
+// changedSettings returns the settings set through the flags, except for the
+// cluster. The --header values are stored as the headers map. The API key,
+// token and Cloud credentials can't be written to the config file.
+func changedSettings(flags *pflag.FlagSet) (map[string]interface{}, error) {
+	var settings = make(map[string]interface{})
+	var err error
+	flags.VisitAll(func(f *pflag.Flag) {
+		switch {
+		case !f.Changed, f.Name == "cluster":
+		case f.Name == "pass":
+			settings[f.Name] = f.Value.String()
+		case isSecretSetting(f.Name):
+			err = fmt.Errorf("--%s can't be stored in the cluster config file, set it with the %s environment variable instead",
+				f.Name, envName(f.Name),
+			)
+		case f.Name == "header":
+			var headers = make(map[string]string)
+			for _, header := range headerFlags {
+				key, value, parseErr := app.ParseHeader(header)
+				if parseErr != nil {
+					err = parseErr
+					return
+				}
+				headers[key] = value
+			}
+			settings["headers"] = headers
+		default:
+			settings[f.Name] = viper.Get(f.Name)
+		}
+	})
+	return settings, err
+}
+
+func isSecretSetting(name string) bool {
+	for _, secret := range secretSettings {
+		if name == secret {
+			return true
+		}
+	}
+	return false
+}
+
+// redactSettings redacts the credentials from the settings
+func redactSettings(settings map[string]interface{}) {
+	for _, key := range secretSettings {
+		if value, ok := settings[key].(string); ok && value != "" {
+			settings[key] = redacted
+		}
+	}
+
+	if proxy, ok := settings["proxy"].(string); ok {
+		if u, err := url.Parse(proxy); err == nil {
+			settings["proxy"] = u.Redacted()
+		}
+	}
+
+	if headers, ok := settings["headers"].(map[string]interface{}); ok {
+		for name, value := range headers {
+			headers[name] = cli.RedactHeader(name, fmt.Sprint(value))
+		}
+	}
+
+	if headers, ok := settings["header"].([]string); ok {
+		var redactedHeaders = make([]string, 0, len(headers))
+		for _, header := range headers {
+			if key, value, err := app.ParseHeader(header); err == nil {
+				header = fmt.Sprintf("%s: %s", key, cli.RedactHeader(key, value))
+			}
+			redactedHeaders = append(redactedHeaders, header)
+		}
+		settings["header"] = redactedHeaders
+	}
+}
+
+func init() {
+	RootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configListCmd, configShowCmd, configAddCmd, configRemoveCmd, configUseCmd, configTestCmd)
+}
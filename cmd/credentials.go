@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/chzyer/readline"
+	"github.com/marclop/elasticsearch-cli/credentials"
+	"github.com/spf13/cobra"
+)
+
+var (
+	credentialsCmd = &cobra.Command{
+		Use:   "credentials",
+		Short: "Manages the cluster passwords kept in the encrypted credential store",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	credentialsSetCmd = &cobra.Command{
+		Use:   "set",
+		Short: "Stores the password of the --cluster, read from the terminal or stdin",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := credentialStore()
+			if err != nil {
+				return err
+			}
+
+			pass, err := readPassword(fmt.Sprintf("Password for %s: ", clusterName()))
+			if err != nil {
+				return err
+			}
+			if pass == "" {
+				return errors.New("the password can't be empty")
+			}
+
+			return store.Set(clusterName(), pass)
+		},
+	}
+
+	credentialsGetCmd = &cobra.Command{
+		Use:   "get",
+		Short: "Prints the stored password of the --cluster",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := credentialStore()
+			if err != nil {
+				return err
+			}
+
+			pass, err := store.Get(clusterName())
+			if err != nil {
+				return storeError(err)
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), pass)
+			return nil
+		},
+	}
+
+	credentialsDeleteCmd = &cobra.Command{
+		Use:   "delete",
+		Short: "Deletes the stored password of the --cluster",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := credentialStore()
+			if err != nil {
+				return err
+			}
+
+			return storeError(store.Delete(clusterName()))
+		},
+	}
+)
+
+// storeError adds the cluster name to credentials.ErrNotFound
+func storeError(err error) error {
+	if err == credentials.ErrNotFound {
+		return fmt.Errorf("no password stored for the \"%s\" cluster", clusterName())
+	}
+	return err
+}
+
+// credentialStore returns the credential store kept in the config directory,
+// protected with ES_CREDENTIALS_PASSPHRASE when it's set
+func credentialStore() (*credentials.Store, error) {
+	dir, err := configDir()
+	if err != nil {
+		return nil, err
+	}
+
+	if passphrase := os.Getenv("ES_CREDENTIALS_PASSPHRASE"); passphrase != "" {
+		return credentials.NewPassphraseStore(dir, passphrase), nil
+	}
+	return credentials.NewStore(dir), nil
+}
+
+// readPassword prompts for the password without echoing it when stdin is a
+// terminal, otherwise its first line is read
+func readPassword(prompt string) (string, error) {
+	if readline.IsTerminal(int(os.Stdin.Fd())) {
+		pass, err := readline.Password(prompt)
+		return string(pass), err
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func init() {
+	RootCmd.AddCommand(credentialsCmd)
+	credentialsCmd.AddCommand(credentialsSetCmd, credentialsGetCmd, credentialsDeleteCmd)
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"github.com/marclop/elasticsearch-cli/app"
+	"github.com/spf13/cobra"
+)
+
+var (
+	runStopOnError bool
+	runContinue    bool
+
+	runCmd = &cobra.Command{
+		Use:   "run [file]",
+		Short: "Runs all of the requests in a Kibana console formatted file (or stdin when no file or \"-\" is specified)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if runStopOnError && runContinue {
+				return errors.New("--stop-on-error and --continue are mutually exclusive")
+			}
+
+			var input io.Reader = os.Stdin
+			if len(args) > 0 && args[0] != "-" {
+				f, err := os.Open(args[0])
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				input = f
+			}
+
+			esCli, err := newApplication()
+			if err != nil {
+				return err
+			}
+
+			return esCli.Run(input, app.RunOptions{StopOnError: runStopOnError})
+		},
+	}
+)
+
+func init() {
+	RootCmd.AddCommand(runCmd)
+	runCmd.Flags().BoolVar(&runStopOnError, "stop-on-error", false, "stop on the first failed request, either with an HTTP error status (>= 400) or when it cannot be performed")
+	runCmd.Flags().BoolVar(&runContinue, "continue", false, "run all of the requests regardless of the failures (default)")
+}
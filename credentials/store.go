@@ -0,0 +1,267 @@
+package credentials
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// storeFile contains the encrypted passwords, it's hidden so it's never
+	// mistaken for a cluster config file
+	storeFile = ".credentials"
+	// keyFile contains the AES-256 key used to encrypt the passwords
+	keyFile = ".credentials.key"
+	// saltFile contains the salt used to derive the key from the passphrase,
+	// it replaces the key file when the store is protected with a passphrase
+	saltFile = ".credentials.salt"
+	keySize  = 32
+	saltSize = 16
+	// keyIterations is the number of PBKDF2-SHA256 iterations used to derive
+	// the key from the passphrase
+	keyIterations = 600000
+)
+
+// ErrNotFound is returned when the cluster has no stored password
+var ErrNotFound = errors.New("no password stored for the cluster")
+
+// Store keeps the cluster passwords encrypted with AES-GCM in a directory.
+// By default the key is kept next to the passwords in a file that's only
+// readable by its owner, which protects the passwords from being leaked with
+// the cluster config files, but not from anyone who can read the user's
+// files. When a passphrase is used, the key is derived from it instead and
+// nothing that decrypts the passwords is stored.
+type Store struct {
+	dir        string
+	passphrase string
+}
+
+// NewStore returns a Store which keeps its files in the directory
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// NewPassphraseStore returns a Store which keeps its files in the directory,
+// with the key derived from the passphrase
+func NewPassphraseStore(dir, passphrase string) *Store {
+	return &Store{dir: dir, passphrase: passphrase}
+}
+
+// Get returns the decrypted password of the cluster
+func (s *Store) Get(cluster string) (string, error) {
+	passwords, err := s.load()
+	if err != nil {
+		return "", err
+	}
+
+	encrypted, ok := passwords[cluster]
+	if !ok {
+		return "", ErrNotFound
+	}
+
+	key, err := s.key(false)
+	if err != nil {
+		return "", err
+	}
+
+	return decrypt(key, cluster, encrypted)
+}
+
+// Set encrypts and stores the password of the cluster, the key is created
+// the first time a password is stored
+func (s *Store) Set(cluster, password string) error {
+	passwords, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	key, err := s.key(true)
+	if err != nil {
+		return err
+	}
+
+	if passwords[cluster], err = encrypt(key, cluster, password); err != nil {
+		return err
+	}
+	return s.save(passwords)
+}
+
+// Delete removes the password of the cluster
+func (s *Store) Delete(cluster string) error {
+	passwords, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := passwords[cluster]; !ok {
+		return ErrNotFound
+	}
+
+	delete(passwords, cluster)
+	return s.save(passwords)
+}
+
+func (s *Store) load() (map[string]string, error) {
+	var passwords = make(map[string]string)
+	content, err := ioutil.ReadFile(filepath.Join(s.dir, storeFile))
+	if os.IsNotExist(err) {
+		return passwords, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(content, &passwords); err != nil {
+		return nil, fmt.Errorf("credential store \"%s\" is corrupted: %s", filepath.Join(s.dir, storeFile), err)
+	}
+	return passwords, nil
+}
+
+func (s *Store) save(passwords map[string]string) error {
+	content, err := json.MarshalIndent(passwords, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(s.dir, storeFile), content, 0600)
+}
+
+// key reads the store key, which must only be accessible by its owner, or
+// derives it from the passphrase. When create is set and the key doesn't
+// exist, a random one is generated.
+func (s *Store) key(create bool) ([]byte, error) {
+	if s.passphrase != "" {
+		return s.passphraseKey(create)
+	}
+
+	if _, err := os.Stat(filepath.Join(s.dir, saltFile)); err == nil {
+		return nil, fmt.Errorf("credential store \"%s\" is protected with a passphrase, set it with ES_CREDENTIALS_PASSPHRASE", s.dir)
+	}
+
+	var path = filepath.Join(s.dir, keyFile)
+	if _, err := os.Stat(path); os.IsNotExist(err) && create {
+		var key = make([]byte, keySize)
+		if _, err := io.ReadFull(rand.Reader, key); err != nil {
+			return nil, err
+		}
+
+		if err := os.MkdirAll(s.dir, 0700); err != nil {
+			return nil, err
+		}
+		return key, ioutil.WriteFile(path, key, 0600)
+	}
+
+	if err := CheckPermissions(path); err != nil {
+		return nil, err
+	}
+
+	key, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(key) != keySize {
+		return nil, fmt.Errorf("credential store key \"%s\" is invalid", path)
+	}
+	return key, nil
+}
+
+// passphraseKey derives the store key from the passphrase and the salt file.
+// When create is set and the salt doesn't exist, a random one is generated.
+func (s *Store) passphraseKey(create bool) ([]byte, error) {
+	if _, err := os.Stat(filepath.Join(s.dir, keyFile)); err == nil {
+		return nil, fmt.Errorf("credential store \"%s\" isn't protected with a passphrase, unset ES_CREDENTIALS_PASSPHRASE or delete its passwords first", s.dir)
+	}
+
+	var path = filepath.Join(s.dir, saltFile)
+	salt, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) && create {
+		salt = make([]byte, saltSize)
+		if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+			return nil, err
+		}
+
+		if err := os.MkdirAll(s.dir, 0700); err != nil {
+			return nil, err
+		}
+		err = ioutil.WriteFile(path, salt, 0600)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(salt) != saltSize {
+		return nil, fmt.Errorf("credential store salt \"%s\" is invalid", path)
+	}
+	return pbkdf2.Key(sha256.New, s.passphrase, salt, keyIterations, keySize)
+}
+
+// CheckPermissions returns an error when the file is accessible by users
+// other than its owner
+func CheckPermissions(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if perm := info.Mode().Perm(); perm&0077 != 0 {
+		return fmt.Errorf("\"%s\" permissions %#o are too open, it must only be accessible by its owner (i.e. 0600)", path, perm)
+	}
+	return nil
+}
+
+// encrypt returns the base64 encoded nonce and ciphertext of the password,
+// the cluster name is authenticated so passwords can't be swapped around
+func encrypt(key []byte, cluster, password string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	var nonce = make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	var sealed = gcm.Seal(nonce, nonce, []byte(password), []byte(cluster))
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func decrypt(key []byte, cluster, encrypted string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil || len(sealed) < gcm.NonceSize() {
+		return "", fmt.Errorf("stored password of \"%s\" is corrupted", cluster)
+	}
+
+	password, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(cluster))
+	if err != nil {
+		return "", fmt.Errorf("stored password of \"%s\" can't be decrypted", cluster)
+	}
+	return string(password), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
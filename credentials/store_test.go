@@ -0,0 +1,154 @@
+package credentials
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestStore(t *testing.T) (*Store, func()) {
+	dir, err := ioutil.TempDir("", "credentials")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewStore(filepath.Join(dir, ".elasticsearch-cli")), func() { os.RemoveAll(dir) }
+}
+
+func TestStore(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	if _, err := store.Get("prod"); err != ErrNotFound {
+		t.Fatalf("Store.Get() error = %v, want %v", err, ErrNotFound)
+	}
+
+	if err := store.Set("prod", "s3cr3t"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set("staging", "other"); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := store.Get("prod")
+	if err != nil || got != "s3cr3t" {
+		t.Errorf("Store.Get() = %v, %v, want %v", got, err, "s3cr3t")
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(store.dir, storeFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), "s3cr3t") {
+		t.Errorf("Store.Set() stored the password in plain text: %s", content)
+	}
+
+	for _, name := range []string{storeFile, keyFile} {
+		if err := CheckPermissions(filepath.Join(store.dir, name)); err != nil {
+			t.Errorf("Store.Set() %s", err)
+		}
+	}
+
+	if err := store.Delete("prod"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get("prod"); err != ErrNotFound {
+		t.Errorf("Store.Delete() error = %v, want %v", err, ErrNotFound)
+	}
+	if err := store.Delete("prod"); err != ErrNotFound {
+		t.Errorf("Store.Delete() error = %v, want %v", err, ErrNotFound)
+	}
+
+	if got, err := store.Get("staging"); err != nil || got != "other" {
+		t.Errorf("Store.Get() = %v, %v, want %v", got, err, "other")
+	}
+}
+
+func TestStore_GetFailsWithSwappedPasswords(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	if err := store.Set("prod", "s3cr3t"); err != nil {
+		t.Fatal(err)
+	}
+
+	passwords, err := store.load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	passwords["staging"] = passwords["prod"]
+	if err := store.save(passwords); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Get("staging"); err == nil || err.Error() != `stored password of "staging" can't be decrypted` {
+		t.Errorf("Store.Get() error = %v", err)
+	}
+}
+
+func TestStore_GetFailsWithOpenKeyPermissions(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	if err := store.Set("prod", "s3cr3t"); err != nil {
+		t.Fatal(err)
+	}
+
+	var path = filepath.Join(store.dir, keyFile)
+	if err := os.Chmod(path, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var want = `"` + path + `" permissions 0644 are too open, it must only be accessible by its owner (i.e. 0600)`
+	if _, err := store.Get("prod"); err == nil || err.Error() != want {
+		t.Errorf("Store.Get() error = %v, want %v", err, want)
+	}
+}
+
+func TestPassphraseStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "credentials")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var store = NewPassphraseStore(dir, "correct horse")
+	if err := store.Set("prod", "s3cr3t"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := store.Get("prod"); err != nil || got != "s3cr3t" {
+		t.Errorf("Store.Get() = %v, %v, want %v", got, err, "s3cr3t")
+	}
+	if _, err := os.Stat(filepath.Join(dir, keyFile)); !os.IsNotExist(err) {
+		t.Errorf("Store.Set() created the key file with a passphrase: %v", err)
+	}
+	if err := CheckPermissions(filepath.Join(dir, saltFile)); err != nil {
+		t.Errorf("Store.Set() %s", err)
+	}
+
+	var want = `stored password of "prod" can't be decrypted`
+	if _, err := NewPassphraseStore(dir, "wrong horse").Get("prod"); err == nil || err.Error() != want {
+		t.Errorf("Store.Get() error = %v, want %v", err, want)
+	}
+
+	want = `credential store "` + dir + `" is protected with a passphrase, set it with ES_CREDENTIALS_PASSPHRASE`
+	if _, err := NewStore(dir).Get("prod"); err == nil || err.Error() != want {
+		t.Errorf("Store.Get() error = %v, want %v", err, want)
+	}
+}
+
+func TestPassphraseStore_FailsWithKeyFile(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	if err := store.Set("prod", "s3cr3t"); err != nil {
+		t.Fatal(err)
+	}
+
+	var want = `credential store "` + store.dir + `" isn't protected with a passphrase, unset ES_CREDENTIALS_PASSPHRASE or delete its passwords first`
+	if _, err := NewPassphraseStore(store.dir, "correct horse").Get("prod"); err == nil || err.Error() != want {
+		t.Errorf("Store.Get() error = %v, want %v", err, want)
+	}
+}
@@ -0,0 +1,19 @@
+package elasticsearch
+
+// Info represents the node and cluster information returned by the root endpoint
+type Info struct {
+	Name        string      `json:"name"`
+	ClusterName string      `json:"cluster_name"`
+	ClusterUUID string      `json:"cluster_uuid"`
+	Version     InfoVersion `json:"version"`
+	Tagline     string      `json:"tagline"`
+}
+
+// InfoVersion contains the Elasticsearch version of the node
+type InfoVersion struct {
+	Number        string `json:"number"`
+	BuildHash     string `json:"build_hash"`
+	BuildDate     string `json:"build_date"`
+	BuildSnapshot bool   `json:"build_snapshot"`
+	LuceneVersion string `json:"lucene_version"`
+}
@@ -2,7 +2,6 @@ module github.com/marclop/elasticsearch-cli
 
 require (
 	github.com/Songmu/retry v0.1.0 // indirect
-	github.com/asaskevich/govalidator v0.0.0-20170425121227-4918b99a7cb9
 	github.com/chzyer/readline v0.0.0-20160726135117-62c6fe619375
 	github.com/cpuguy83/go-md2man v1.0.7
 	github.com/fsnotify/fsnotify v0.0.0-20170329110642-4da3e2cfbabc
@@ -12,6 +11,7 @@ require (
 	github.com/hashicorp/go-version v1.1.0 // indirect
 	github.com/hashicorp/hcl v0.0.0-20170914154624-68e816d1c783
 	github.com/inconshreveable/mousetrap v1.0.0
+	github.com/jmespath/go-jmespath v0.0.0-20180206201540-c2b33e8439af
 	github.com/magiconair/properties v0.0.0-20170902060319-8d7837e64d3c
 	github.com/mattn/go-colorable v0.1.0 // indirect
 	github.com/mattn/go-isatty v0.0.4 // indirect
@@ -2,9 +2,11 @@ package poller
 
 import (
 	"encoding/json"
+	"io"
 	"io/ioutil"
 	"log"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
@@ -15,7 +17,7 @@ const defaultPollingEndpoint = "/_cat/indices"
 
 // client abstracts the real client used by the poller
 type client interface {
-	HandleCall(method, url, body string) (*http.Response, error)
+	HandleCall(method, path string, query url.Values, body io.Reader) (*http.Response, error)
 }
 
 // IndexPoller polls the ElasticSearch API to discover which indices exist
@@ -63,7 +65,7 @@ func (w *IndexPoller) Stop() {
 }
 
 func (w *IndexPoller) run() []string {
-	res, err := w.client.HandleCall("GET", w.endpoint, "")
+	res, err := w.client.HandleCall("GET", w.endpoint, nil, nil)
 	if err != nil {
 		log.Print("[ERROR]: ", err)
 		return nil
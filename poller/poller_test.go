@@ -2,8 +2,10 @@ package poller
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"reflect"
 	"strings"
 	"testing"
@@ -16,7 +18,7 @@ type mockClient struct {
 	header  http.Header
 }
 
-func (c *mockClient) HandleCall(_, _, _ string) (*http.Response, error) {
+func (c *mockClient) HandleCall(_, _ string, _ url.Values, _ io.Reader) (*http.Response, error) {
 	var err error
 	if c.fail {
 		err = fmt.Errorf("fail")
@@ -0,0 +1,161 @@
+package profile
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// DefaultCluster is used when no default cluster has been set
+	DefaultCluster = "default"
+	// defaultClusterFile contains the name of the default cluster
+	defaultClusterFile = "default-cluster"
+)
+
+// validName matches the cluster names which can be used as file names
+var validName = regexp.MustCompile(`^[a-zA-Z0-9_-][a-zA-Z0-9_.-]*$`)
+
+// Profiles manages the cluster config files, named <cluster>.<ext>, and the
+// default cluster pointer stored in a directory
+type Profiles struct {
+	dir string
+}
+
+// New returns the Profiles stored in the directory
+func New(dir string) *Profiles {
+	return &Profiles{dir: dir}
+}
+
+// List returns the sorted names of the clusters which have a config file
+func (p *Profiles) List() ([]string, error) {
+	files, err := ioutil.ReadDir(p.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var seen = make(map[string]bool)
+	var names []string
+	for _, f := range files {
+		var ext = strings.TrimPrefix(filepath.Ext(f.Name()), ".")
+		var name = strings.TrimSuffix(f.Name(), filepath.Ext(f.Name()))
+		if f.IsDir() || !supportedExt(ext) || !validName.MatchString(name) || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Path returns the config file of the cluster, false when it doesn't exist
+func (p *Profiles) Path(name string) (string, bool) {
+	for _, ext := range viper.SupportedExts {
+		var path = filepath.Join(p.dir, name+"."+ext)
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// Add creates the cluster YAML config file with the settings, which is only
+// readable by its owner since it can contain credentials
+func (p *Profiles) Add(name string, settings map[string]interface{}) (string, error) {
+	if err := validateName(name); err != nil {
+		return "", err
+	}
+
+	if path, ok := p.Path(name); ok {
+		return "", fmt.Errorf("cluster \"%s\" already exists in \"%s\"", name, path)
+	}
+
+	content, err := yaml.Marshal(settings)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(p.dir, 0700); err != nil {
+		return "", err
+	}
+
+	var path = filepath.Join(p.dir, name+".yaml")
+	return path, ioutil.WriteFile(path, content, 0600)
+}
+
+// Remove deletes the cluster config file, unsetting the default cluster when
+// it's the removed cluster
+func (p *Profiles) Remove(name string) error {
+	path, ok := p.Path(name)
+	if !ok {
+		return fmt.Errorf("cluster \"%s\" doesn't exist", name)
+	}
+
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+
+	if p.Default() == name {
+		if err := os.Remove(filepath.Join(p.dir, defaultClusterFile)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Default returns the default cluster, DefaultCluster when it's not set
+func (p *Profiles) Default() string {
+	content, err := ioutil.ReadFile(filepath.Join(p.dir, defaultClusterFile))
+	if err != nil {
+		return DefaultCluster
+	}
+
+	var name = strings.TrimSpace(string(content))
+	if validateName(name) != nil {
+		return DefaultCluster
+	}
+	return name
+}
+
+// SetDefault sets the cluster used when none is specified, which must exist
+func (p *Profiles) SetDefault(name string) error {
+	if err := validateName(name); err != nil {
+		return err
+	}
+
+	if _, ok := p.Path(name); !ok {
+		return fmt.Errorf("cluster \"%s\" doesn't exist", name)
+	}
+
+	if err := os.MkdirAll(p.dir, 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(p.dir, defaultClusterFile), []byte(name+"\n"), 0600)
+}
+
+func validateName(name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("cluster name \"%s\" is invalid, must only contain letters, digits, \".\", \"_\" and \"-\"", name)
+	}
+	return nil
+}
+
+func supportedExt(ext string) bool {
+	for _, supported := range viper.SupportedExts {
+		if ext == supported {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package profile
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func newTestProfiles(t *testing.T) (*Profiles, func()) {
+	dir, err := ioutil.TempDir("", "profile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(filepath.Join(dir, ".elasticsearch-cli")), func() { os.RemoveAll(dir) }
+}
+
+func TestProfiles(t *testing.T) {
+	profiles, cleanup := newTestProfiles(t)
+	defer cleanup()
+
+	if names, err := profiles.List(); err != nil || names != nil {
+		t.Errorf("Profiles.List() = %v, %v, want no clusters", names, err)
+	}
+	if got := profiles.Default(); got != DefaultCluster {
+		t.Errorf("Profiles.Default() = %v, want %v", got, DefaultCluster)
+	}
+
+	path, err := profiles.Add("prod", map[string]interface{}{"host": "https://prod:9243", "user": "marc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "host: https://prod:9243\nuser: marc\n" {
+		t.Errorf("Profiles.Add() = %v", string(content))
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("Profiles.Add() permissions = %#o, want 0600", info.Mode().Perm())
+	}
+
+	if _, err := profiles.Add("prod", nil); err == nil {
+		t.Error("Profiles.Add() expected an error adding an existing cluster")
+	}
+	if _, err := profiles.Add("../prod", nil); err == nil {
+		t.Error("Profiles.Add() expected an error with an invalid name")
+	}
+
+	// Hand written config files and other files in the directory
+	for _, name := range []string{"staging.json", ".credentials", "default-cluster.bak", "notes.txt"} {
+		if err := ioutil.WriteFile(filepath.Join(profiles.dir, name), []byte("{}"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if names, err := profiles.List(); err != nil || !reflect.DeepEqual(names, []string{"prod", "staging"}) {
+		t.Errorf("Profiles.List() = %v, %v, want %v", names, err, []string{"prod", "staging"})
+	}
+
+	if err := profiles.SetDefault("qa"); err == nil {
+		t.Error("Profiles.SetDefault() expected an error with a missing cluster")
+	}
+	if err := profiles.SetDefault("prod"); err != nil {
+		t.Fatal(err)
+	}
+	if got := profiles.Default(); got != "prod" {
+		t.Errorf("Profiles.Default() = %v, want %v", got, "prod")
+	}
+
+	if err := profiles.Remove("prod"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := profiles.Path("prod"); ok {
+		t.Error("Profiles.Remove() didn't remove the config file")
+	}
+	if got := profiles.Default(); got != DefaultCluster {
+		t.Errorf("Profiles.Default() = %v, want %v after removing it", got, DefaultCluster)
+	}
+	if err := profiles.Remove("prod"); err == nil {
+		t.Error("Profiles.Remove() expected an error with a missing cluster")
+	}
+}